@@ -3,6 +3,8 @@ package main
 import (
 	"log"
 	"os"
+	// embedded zone database: the scratch image has none, analytics accept IANA time zones
+	_ "time/tzdata"
 
 	"github.com/andreyxaxa/URL-Shortener/config"
 	"github.com/andreyxaxa/URL-Shortener/internal/app"
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/andreyxaxa/URL-Shortener/config"
+	"github.com/andreyxaxa/URL-Shortener/internal/repo/persistent"
+	"github.com/andreyxaxa/URL-Shortener/internal/usecase/importer"
+	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
+	"github.com/andreyxaxa/URL-Shortener/pkg/postgres"
+	"github.com/joho/godotenv"
+)
+
+// Imports links from another shortener:
+//
+//	go run ./cmd/import -file links.csv [-format csv|ndjson] [-dry-run]
+func main() {
+	file := flag.String("file", "-", "path to CSV or NDJSON file, - for stdin")
+	format := flag.String("format", "", "csv or ndjson, detected by file extension if empty")
+	dryRun := flag.Bool("dry-run", false, "validate and check conflicts without writing")
+	flag.Parse()
+
+	if _, err := os.Stat(".env"); err == nil {
+		err = godotenv.Load()
+		if err != nil {
+			log.Fatalf("config error: %s", err)
+		}
+	}
+
+	cfg, err := config.NewImport()
+	if err != nil {
+		log.Fatalf("config error: %s", err)
+	}
+
+	// exit only here, so deferred cleanup in run has happened
+	err = run(cfg, *file, *format, *dryRun)
+	if err != nil {
+		log.Printf("import error: %s", err)
+		os.Exit(1)
+	}
+}
+
+func run(cfg *config.Import, file, format string, dryRun bool) error {
+	if format == "" {
+		format = strings.TrimPrefix(filepath.Ext(file), ".")
+	}
+
+	var in io.Reader = os.Stdin
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		in = f
+	}
+
+	l := logger.New(cfg.LogLevel)
+
+	pg, err := postgres.New(cfg.PG.URL, postgres.MaxPoolSize(cfg.PG.PoolMax))
+	if err != nil {
+		return fmt.Errorf("postgres.New: %w", err)
+	}
+	defer pg.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	report, err := importer.New(persistent.New(pg), l).Import(ctx, in, format, dryRun)
+
+	// the report tells which rows were imported even when the import stopped halfway
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(report)
+
+	return err
+}
@@ -2,17 +2,27 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
 
 type (
 	Config struct {
-		HTTP    HTTP
-		Log     Log
-		PG      PG
-		Redis   Redis
-		Swagger Swagger
+		HTTP            HTTP
+		Log             Log
+		PG              PG
+		Redis           Redis
+		Swagger         Swagger
+		Clicks          Clicks
+		Purge           Purge
+		Rollup          Rollup
+		ClickPartitions ClickPartitions
+		Visitors        Visitors
+		GeoIP           GeoIP
+		Privacy         Privacy
+		Stream          Stream
+		Codes           Codes
 	}
 
 	HTTP struct {
@@ -40,6 +50,74 @@ type (
 	Swagger struct {
 		Enabled bool `env:"SWAGGER_ENABLED" envDefault:"false"`
 	}
+
+	Clicks struct {
+		Workers         int           `env:"CLICKS_WORKERS" envDefault:"4"`
+		QueueSize       int           `env:"CLICKS_QUEUE_SIZE" envDefault:"10000"`
+		ShutdownTimeout time.Duration `env:"CLICKS_SHUTDOWN_TIMEOUT" envDefault:"5s"`
+		BatchSize       int           `env:"CLICKS_BATCH_SIZE" envDefault:"500"`
+		FlushInterval   time.Duration `env:"CLICKS_FLUSH_INTERVAL" envDefault:"1s"`
+		DedupWindow     time.Duration `env:"CLICKS_DEDUP_WINDOW" envDefault:"10s"`
+		DedupMode       string        `env:"CLICKS_DEDUP_MODE" envDefault:"flag"`
+	}
+
+	Rollup struct {
+		Enabled  bool          `env:"ROLLUP_ENABLED" envDefault:"true"`
+		Interval time.Duration `env:"ROLLUP_INTERVAL" envDefault:"1m"`
+		Lag      time.Duration `env:"ROLLUP_LAG" envDefault:"5m"`
+	}
+
+	ClickPartitions struct {
+		Enabled   bool          `env:"CLICK_PARTITIONS_ENABLED" envDefault:"true"`
+		Interval  time.Duration `env:"CLICK_PARTITIONS_INTERVAL" envDefault:"1h"`
+		Ahead     int           `env:"CLICK_PARTITIONS_AHEAD" envDefault:"3"`
+		Retention time.Duration `env:"CLICK_RETENTION" envDefault:"0"`
+	}
+
+	Visitors struct {
+		Salt   string        `env:"VISITORS_SALT"`
+		DayTTL time.Duration `env:"VISITORS_DAY_TTL" envDefault:"9600h"`
+	}
+
+	GeoIP struct {
+		DBPath string `env:"GEOIP_DB_PATH"`
+	}
+
+	Privacy struct {
+		Mode              string        `env:"PRIVACY_MODE" envDefault:"full"`
+		IPv4PrefixBits    int           `env:"PRIVACY_IPV4_PREFIX" envDefault:"24"`
+		IPv6PrefixBits    int           `env:"PRIVACY_IPV6_PREFIX" envDefault:"48"`
+		HashRotation      time.Duration `env:"PRIVACY_HASH_ROTATION" envDefault:"24h"`
+		HonorDNT          bool          `env:"PRIVACY_HONOR_DNT" envDefault:"true"`
+		AnonymizeAfter    time.Duration `env:"PRIVACY_ANONYMIZE_AFTER" envDefault:"0"`
+		AnonymizeInterval time.Duration `env:"PRIVACY_ANONYMIZE_INTERVAL" envDefault:"1h"`
+	}
+
+	Stream struct {
+		MaxConnections int           `env:"STREAM_MAX_CONNECTIONS" envDefault:"100"`
+		Buffer         int           `env:"STREAM_BUFFER" envDefault:"64"`
+		Lifetime       time.Duration `env:"STREAM_LIFETIME" envDefault:"1h"`
+	}
+
+	Purge struct {
+		Enabled     bool          `env:"PURGE_ENABLED" envDefault:"true"`
+		Interval    time.Duration `env:"PURGE_INTERVAL" envDefault:"10m"`
+		GracePeriod time.Duration `env:"PURGE_GRACE_PERIOD" envDefault:"24h"`
+		BatchSize   int           `env:"PURGE_BATCH_SIZE" envDefault:"1000"`
+	}
+
+	// Import is the configuration of the import CLI, it only writes to Postgres
+	Import struct {
+		PG       PG
+		LogLevel string `env:"LOG_LEVEL" envDefault:"info"`
+	}
+
+	Codes struct {
+		Strategy    string `env:"CODE_STRATEGY" envDefault:"sequential"`
+		Length      int    `env:"CODE_LENGTH" envDefault:"8"`
+		Secret      string `env:"CODE_SECRET"`
+		IDBlockSize int    `env:"CODE_ID_BLOCK_SIZE" envDefault:"100"`
+	}
 )
 
 func New() (*Config, error) {
@@ -49,5 +127,20 @@ func New() (*Config, error) {
 		return nil, fmt.Errorf("config error: %v", err)
 	}
 
+	// a non-positive limit breaks the purge query
+	if cfg.Purge.BatchSize <= 0 {
+		return nil, fmt.Errorf("config error: PURGE_BATCH_SIZE must be positive, got %d", cfg.Purge.BatchSize)
+	}
+
+	return cfg, nil
+}
+
+func NewImport() (*Import, error) {
+	cfg := &Import{}
+
+	if err := env.Parse(cfg); err != nil {
+		return nil, fmt.Errorf("config error: %v", err)
+	}
+
 	return cfg, nil
 }
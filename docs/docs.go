@@ -38,15 +38,47 @@ const docTemplate = `{
                     },
                     {
                         "enum": [
+                            "hour",
                             "day",
+                            "week",
                             "month",
                             "device",
-                            "browser"
+                            "os",
+                            "browser",
+                            "referrer",
+                            "country",
+                            "bot"
                         ],
                         "type": "string",
                         "description": "Group critery",
                         "name": "group-by",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range, RFC3339 or YYYY-MM-DD (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range, RFC3339 or YYYY-MM-DD (exclusive, a date includes the whole day)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone for day/month buckets and dates in from/to",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Count clicks of bots and link previews",
+                        "name": "include_bots",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -57,7 +89,7 @@ const docTemplate = `{
                         }
                     },
                     "201": {
-                        "description": "Analytics by date (group-by=day/month)",
+                        "description": "Analytics by date (group-by=hour/day/week/month), empty buckets have zero clicks",
                         "schema": {
                             "$ref": "#/definitions/response.GetAnalyticsByDateResponse"
                         }
@@ -74,6 +106,442 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.GetAnalyticsByDeviceResponse"
                         }
                     },
+                    "204": {
+                        "description": "Analytics by referring host (group-by=referrer), empty host is direct traffic",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetAnalyticsByReferrerResponse"
+                        }
+                    },
+                    "205": {
+                        "description": "Analytics by ISO country code (group-by=country), empty when unknown",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetAnalyticsByCountryResponse"
+                        }
+                    },
+                    "206": {
+                        "description": "Human and bot clicks (group-by=bot), bots are always counted",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetAnalyticsByBotResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Analytics by operating system (group-by=os)",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetAnalyticsByOSResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/analytics/{short}/stream": {
+            "get": {
+                "description": "Pushes clicks of the short link as Server-Sent Events while they are tracked by any replica.\nEvery click is a \"click\" event with a JSON body, \"missed\" counts events dropped before it\nbecause the client was reading too slowly. The stream is closed after a configured lifetime,\nEventSource clients reconnect on their own.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Live clicks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/entity.ClickEvent"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/export/links": {
+            "get": {
+                "description": "Streams all links as CSV (same header as import) or NDJSON",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Export links",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "links",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/import/links": {
+            "post": {
+                "description": "Imports code -\u003e URL mappings from CSV (header: short_code,url[,created_at][,expires_at]) or NDJSON.\nCodes are kept as custom aliases, taken codes are reported as conflicts. For big files use cmd/import.",
+                "consumes": [
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Import links",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "File format",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and check conflicts without writing",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "File content",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ImportLinksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/links": {
+            "get": {
+                "description": "Returns links, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "List links",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size (1-100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.ListLinksResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/links/{short}": {
+            "get": {
+                "description": "Returns link metadata",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Get link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short Code",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetLinkResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes link with all its clicks",
+                "tags": [
+                    "links"
+                ],
+                "summary": "Delete link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short Code",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Deleted"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Changes destination and/or expiration of the link",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Update link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short Code",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/request.UpdateLinkRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetLinkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/links/{short}/clicks/export": {
+            "get": {
+                "description": "Streams raw clicks of the short link ordered by time as CSV or NDJSON.\nfrom/to accept RFC3339 or YYYY-MM-DD, a date-only \"to\" includes the whole day.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "analytics"
+                ],
+                "summary": "Export clicks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Short code",
+                        "name": "short",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "default": "csv",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the range (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the range (exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "UTC",
+                        "description": "IANA time zone for date-only from/to",
+                        "name": "tz",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "Export clicks of bots and link previews too",
+                        "name": "include_bots",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "clicks",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
@@ -124,6 +592,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/response.Error"
                         }
                     },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -178,28 +652,228 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "entity.ClickByBrowser": {
+        },
+        "/v1/shorten/batch": {
+            "post": {
+                "description": "Creates up to 1000 short URLs. Every item is validated and created independently,\nfailed items are reported with an error and don't affect the rest of the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "links"
+                ],
+                "summary": "Create short URLs in bulk",
+                "parameters": [
+                    {
+                        "description": "Links",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/request.CreateShortURLRequest"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.CreateShortURLsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/response.Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/stats/clicks": {
+            "get": {
+                "description": "Returns depth and counters of the click-tracking queue",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Click queue stats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/response.GetClickStatsResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "entity.ClickByBot": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "is_bot": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "entity.ClickByBrowser": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string"
+                },
+                "clicks": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ClickByCountry": {
+            "type": "object",
+            "properties": {
+                "clicks": {
+                    "type": "integer"
+                },
+                "country": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ClickByDevice": {
             "type": "object",
             "properties": {
-                "browser": {
-                    "type": "string"
+                "clicks": {
+                    "type": "integer"
                 },
+                "device": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ClickByOS": {
+            "type": "object",
+            "properties": {
                 "clicks": {
                     "type": "integer"
+                },
+                "os": {
+                    "type": "string"
                 }
             }
         },
-        "entity.ClickByDevice": {
+        "entity.ClickByReferrer": {
             "type": "object",
             "properties": {
                 "clicks": {
                     "type": "integer"
                 },
+                "referrer": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ClickEvent": {
+            "type": "object",
+            "properties": {
+                "browser": {
+                    "type": "string"
+                },
+                "clicked_at": {
+                    "type": "string"
+                },
+                "country": {
+                    "type": "string"
+                },
                 "device": {
                     "type": "string"
+                },
+                "is_bot": {
+                    "type": "boolean"
+                },
+                "missed": {
+                    "description": "Missed is the number of events dropped before this one because the subscriber was too slow",
+                    "type": "integer"
+                },
+                "short_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ClickQueueStats": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer"
+                },
+                "depth": {
+                    "type": "integer"
+                },
+                "dropped": {
+                    "type": "integer"
+                },
+                "enqueued": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "processed": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.ImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "short_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "entity.ImportReport": {
+            "type": "object",
+            "properties": {
+                "conflicts": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ImportError"
+                    }
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "imported": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
                 }
             }
         },
@@ -209,6 +883,29 @@ const docTemplate = `{
                 "custom_alias": {
                     "type": "string"
                 },
+                "expires_at": {
+                    "type": "string"
+                },
+                "ttl_seconds": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "request.UpdateLinkRequest": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "no_expiry": {
+                    "type": "boolean"
+                },
+                "ttl_seconds": {
+                    "type": "integer"
+                },
                 "url": {
                     "type": "string"
                 }
@@ -217,18 +914,45 @@ const docTemplate = `{
         "response.Analytics": {
             "type": "object",
             "properties": {
+                "clicks_by_bot": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByBot"
+                    }
+                },
                 "clicks_by_browser": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/entity.ClickByBrowser"
                     }
                 },
+                "clicks_by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByCountry"
+                    }
+                },
                 "clicks_by_device": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/entity.ClickByDevice"
                     }
                 },
+                "clicks_by_os": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByOS"
+                    }
+                },
+                "clicks_by_referrer": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByReferrer"
+                    }
+                },
+                "deduplicated_clicks": {
+                    "type": "integer"
+                },
                 "recent_clicks": {
                     "type": "array",
                     "items": {
@@ -237,6 +961,20 @@ const docTemplate = `{
                 },
                 "total_clicks": {
                     "type": "integer"
+                },
+                "unique_visitors": {
+                    "type": "integer"
+                }
+            }
+        },
+        "response.AnalyticsByBot": {
+            "type": "object",
+            "properties": {
+                "clicks_by_bot": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByBot"
+                    }
                 }
             }
         },
@@ -251,6 +989,17 @@ const docTemplate = `{
                 }
             }
         },
+        "response.AnalyticsByCountry": {
+            "type": "object",
+            "properties": {
+                "clicks_by_country": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByCountry"
+                    }
+                }
+            }
+        },
         "response.AnalyticsByDate": {
             "type": "object",
             "properties": {
@@ -273,6 +1022,28 @@ const docTemplate = `{
                 }
             }
         },
+        "response.AnalyticsByOS": {
+            "type": "object",
+            "properties": {
+                "clicks_by_os": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByOS"
+                    }
+                }
+            }
+        },
+        "response.AnalyticsByReferrer": {
+            "type": "object",
+            "properties": {
+                "clicks_by_referrer": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.ClickByReferrer"
+                    }
+                }
+            }
+        },
         "response.ClickByDate": {
             "type": "object",
             "properties": {
@@ -281,12 +1052,35 @@ const docTemplate = `{
                 },
                 "date": {
                     "type": "string"
+                },
+                "unique_visitors": {
+                    "type": "integer"
                 }
             }
         },
         "response.CreateShortURLResponse": {
             "type": "object",
             "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.CreateShortURLResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
                 "original_url": {
                     "type": "string"
                 },
@@ -295,6 +1089,23 @@ const docTemplate = `{
                 }
             }
         },
+        "response.CreateShortURLsResponse": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.CreateShortURLResult"
+                    }
+                }
+            }
+        },
         "response.Error": {
             "type": "object",
             "properties": {
@@ -303,6 +1114,14 @@ const docTemplate = `{
                 }
             }
         },
+        "response.GetAnalyticsByBotResponse": {
+            "type": "object",
+            "properties": {
+                "analytics": {
+                    "$ref": "#/definitions/response.AnalyticsByBot"
+                }
+            }
+        },
         "response.GetAnalyticsByBrowserResponse": {
             "type": "object",
             "properties": {
@@ -311,6 +1130,14 @@ const docTemplate = `{
                 }
             }
         },
+        "response.GetAnalyticsByCountryResponse": {
+            "type": "object",
+            "properties": {
+                "analytics": {
+                    "$ref": "#/definitions/response.AnalyticsByCountry"
+                }
+            }
+        },
         "response.GetAnalyticsByDateResponse": {
             "type": "object",
             "properties": {
@@ -327,6 +1154,22 @@ const docTemplate = `{
                 }
             }
         },
+        "response.GetAnalyticsByOSResponse": {
+            "type": "object",
+            "properties": {
+                "analytics": {
+                    "$ref": "#/definitions/response.AnalyticsByOS"
+                }
+            }
+        },
+        "response.GetAnalyticsByReferrerResponse": {
+            "type": "object",
+            "properties": {
+                "analytics": {
+                    "$ref": "#/definitions/response.AnalyticsByReferrer"
+                }
+            }
+        },
         "response.GetAnalyticsResponse": {
             "type": "object",
             "properties": {
@@ -334,6 +1177,73 @@ const docTemplate = `{
                     "$ref": "#/definitions/response.Analytics"
                 }
             }
+        },
+        "response.GetClickStatsResponse": {
+            "type": "object",
+            "properties": {
+                "queue": {
+                    "$ref": "#/definitions/entity.ClickQueueStats"
+                }
+            }
+        },
+        "response.GetLinkResponse": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "$ref": "#/definitions/response.Link"
+                }
+            }
+        },
+        "response.ImportLinksResponse": {
+            "type": "object",
+            "properties": {
+                "report": {
+                    "$ref": "#/definitions/entity.ImportReport"
+                }
+            }
+        },
+        "response.Link": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "is_custom": {
+                    "type": "boolean"
+                },
+                "original_url": {
+                    "type": "string"
+                },
+                "short_code": {
+                    "type": "string"
+                },
+                "short_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ListLinksResponse": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "links": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/response.Link"
+                    }
+                },
+                "offset": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
         }
     }
 }`
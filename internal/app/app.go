@@ -1,20 +1,28 @@
 package app
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/andreyxaxa/URL-Shortener/config"
 	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi"
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
 	"github.com/andreyxaxa/URL-Shortener/internal/repo/cache"
 	"github.com/andreyxaxa/URL-Shortener/internal/repo/persistent"
+	"github.com/andreyxaxa/URL-Shortener/internal/usecase/importer"
 	"github.com/andreyxaxa/URL-Shortener/internal/usecase/link"
+	"github.com/andreyxaxa/URL-Shortener/pkg/encoder"
+	"github.com/andreyxaxa/URL-Shortener/pkg/geoip"
 	"github.com/andreyxaxa/URL-Shortener/pkg/httpserver"
 	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
 	"github.com/andreyxaxa/URL-Shortener/pkg/postgres"
 	"github.com/andreyxaxa/URL-Shortener/pkg/redis"
+	"github.com/andreyxaxa/URL-Shortener/pkg/scheduler"
+	"github.com/andreyxaxa/URL-Shortener/pkg/workerpool"
 )
 
 func Run(cfg *config.Config) {
@@ -35,16 +43,143 @@ func Run(cfg *config.Config) {
 	}
 	defer rd.Close()
 
+	// Short code generator
+	codes, err := encoder.NewCodeGenerator(cfg.Codes.Strategy, cfg.Codes.Length, cfg.Codes.Secret)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - encoder.NewCodeGenerator: %v", err))
+	}
+
+	// GeoIP, optional
+	var geo *geoip.Locator
+	if cfg.GeoIP.DBPath != "" {
+		geo, err = geoip.New(cfg.GeoIP.DBPath)
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - geoip.New: %v", err))
+		}
+		defer geo.Close()
+	} else {
+		l.Info("app - Run - geoip: GEOIP_DB_PATH is not set, country lookup is disabled")
+	}
+
+	dedupMode, err := link.ParseDedupMode(cfg.Clicks.DedupMode)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - link.ParseDedupMode: %v", err))
+	}
+
+	privacyMode, err := link.ParsePrivacyMode(cfg.Privacy.Mode)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - link.ParsePrivacyMode: %v", err))
+	}
+
+	// Click worker pool
+	clickPool := workerpool.New[entity.Click](l,
+		workerpool.Workers(cfg.Clicks.Workers),
+		workerpool.QueueSize(cfg.Clicks.QueueSize),
+		workerpool.ShutdownTimeout(cfg.Clicks.ShutdownTimeout),
+		workerpool.BatchSize(cfg.Clicks.BatchSize),
+		workerpool.FlushInterval(cfg.Clicks.FlushInterval),
+	)
+
+	// Repository
+	linkRepo := persistent.New(pg)
+
 	// Use-Case
 	linkUseCase := link.New(
-		persistent.New(pg),
+		linkRepo,
 		cache.New(rd),
+		clickPool,
+		codes,
 		l,
+		link.IDBlockSize(cfg.Codes.IDBlockSize),
+		link.VisitorSalt(cfg.Visitors.Salt),
+		link.UniqueDayTTL(cfg.Visitors.DayTTL),
+		link.GeoIP(geo),
+		link.Dedup(cfg.Clicks.DedupWindow, dedupMode),
+		link.Privacy(privacyMode, cfg.Privacy.IPv4PrefixBits, cfg.Privacy.IPv6PrefixBits, cfg.Privacy.HashRotation),
+		link.HonorDoNotTrack(cfg.Privacy.HonorDNT),
+		link.Stream(cfg.Stream.MaxConnections, cfg.Stream.Buffer, cfg.Stream.Lifetime),
 	)
 
+	importUseCase := importer.New(linkRepo, l)
+
+	// clicks has no default partition: months clicks are written to must exist before the first click,
+	// whether or not the partition job is enabled
+	created, _, err := linkUseCase.MaintainClickPartitions(context.Background(), max(cfg.ClickPartitions.Ahead, 1), 0)
+	if err != nil {
+		l.Fatal(fmt.Errorf("app - Run - linkUseCase.MaintainClickPartitions: %v", err))
+	}
+	if len(created) > 0 {
+		l.Info("app - Run - click-partitions: created %v", created)
+	}
+
+	// Start click workers
+	clickPool.Start(linkUseCase.ProcessClicks)
+
+	// Background jobs
+	sched := scheduler.New(l)
+
+	if cfg.Purge.Enabled {
+		err = sched.Add("purge-expired-links", cfg.Purge.Interval, func(ctx context.Context) error {
+			n, err := linkUseCase.PurgeExpiredLinks(ctx, time.Now().Add(-cfg.Purge.GracePeriod), cfg.Purge.BatchSize)
+			if n > 0 {
+				l.Info("app - Run - purge-expired-links: deleted %d links", n)
+			}
+
+			return err
+		})
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - sched.Add: %v", err))
+		}
+	}
+
+	if cfg.Rollup.Enabled {
+		err = sched.Add("rollup-clicks", cfg.Rollup.Interval, func(ctx context.Context) error {
+			_, err := linkUseCase.RollupClicks(ctx, cfg.Rollup.Lag)
+
+			return err
+		})
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - sched.Add: %v", err))
+		}
+	}
+
+	if cfg.ClickPartitions.Enabled {
+		err = sched.Add("click-partitions", cfg.ClickPartitions.Interval, func(ctx context.Context) error {
+			created, dropped, err := linkUseCase.MaintainClickPartitions(ctx,
+				cfg.ClickPartitions.Ahead, cfg.ClickPartitions.Retention)
+			if len(created) > 0 {
+				l.Info("app - Run - click-partitions: created %v", created)
+			}
+			if len(dropped) > 0 {
+				l.Info("app - Run - click-partitions: dropped %v", dropped)
+			}
+
+			return err
+		})
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - sched.Add: %v", err))
+		}
+	}
+
+	if cfg.Privacy.AnonymizeAfter > 0 {
+		err = sched.Add("anonymize-clicks", cfg.Privacy.AnonymizeInterval, func(ctx context.Context) error {
+			_, err := linkUseCase.AnonymizeClicks(ctx, cfg.Privacy.AnonymizeAfter)
+
+			return err
+		})
+		if err != nil {
+			l.Fatal(fmt.Errorf("app - Run - sched.Add: %v", err))
+		}
+	}
+
+	sched.Start()
+
 	// HTTP Server
 	httpServer := httpserver.New(l, httpserver.Port(cfg.HTTP.Port))
-	restapi.NewRouter(httpServer.App, cfg, linkUseCase, l, fmt.Sprintf("http://localhost:%s", cfg.HTTP.Port))
+	// live streams never end on their own, they are cancelled before shutdown so it doesn't wait for them
+	streams, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+	restapi.NewRouter(streams, httpServer.App, cfg, linkUseCase, importUseCase, l, fmt.Sprintf("http://localhost:%s", cfg.HTTP.Port))
 
 	// Start server
 	httpServer.Start()
@@ -60,5 +195,21 @@ func Run(cfg *config.Config) {
 		l.Error(fmt.Errorf("app - Run - httpServer.Notify: %v", err))
 	}
 
+	stopStreams()
+
 	err = httpServer.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - httpServer.Shutdown: %v", err))
+	}
+
+	err = sched.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - sched.Shutdown: %v", err))
+	}
+
+	// Drain clicks after the server stopped accepting requests
+	err = clickPool.Shutdown()
+	if err != nil {
+		l.Error(fmt.Errorf("app - Run - clickPool.Shutdown: %v", err))
+	}
 }
@@ -1,6 +1,8 @@
 package restapi
 
 import (
+	"context"
+
 	"github.com/andreyxaxa/URL-Shortener/config"
 	_ "github.com/andreyxaxa/URL-Shortener/docs" // Swagger docs.
 	v1 "github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1"
@@ -14,7 +16,9 @@ import (
 // @version 1.0
 // @host localhost:8080
 // @BasePath /v1
-func NewRouter(app *fiber.App, cfg *config.Config, lk usecase.Link, l logger.Interface, baseURL string) {
+func NewRouter(streams context.Context, app *fiber.App, cfg *config.Config, lk usecase.Link, im usecase.Importer,
+	l logger.Interface, baseURL string,
+) {
 	// Swagger
 	if cfg.Swagger.Enabled {
 		app.Get("/swagger/*", swagger.HandlerDefault)
@@ -23,6 +27,6 @@ func NewRouter(app *fiber.App, cfg *config.Config, lk usecase.Link, l logger.Int
 	// Routers
 	apiV1Group := app.Group("/v1")
 	{
-		v1.NewLinkRoutes(apiV1Group, lk, l, baseURL+"/v1")
+		v1.NewLinkRoutes(streams, apiV1Group, lk, im, l, baseURL+"/v1")
 	}
 }
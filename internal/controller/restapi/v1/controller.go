@@ -1,13 +1,19 @@
 package v1
 
 import (
+	"context"
+
 	"github.com/andreyxaxa/URL-Shortener/internal/usecase"
 	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
 )
 
 type V1 struct {
 	lk usecase.Link
+	im usecase.Importer
 	l  logger.Interface
 
+	// streams is cancelled before the server shuts down, live streams would keep it waiting otherwise
+	streams context.Context
+
 	baseURL string
 }
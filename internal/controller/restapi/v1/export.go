@@ -0,0 +1,262 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	_exportFormatCSV    = "csv"
+	_exportFormatNDJSON = "ndjson"
+
+	// rows written between flushes, every flush also extends the connection write deadline
+	_exportFlushEvery   = 500
+	_exportWriteTimeout = 30 * time.Second
+)
+
+var (
+	linkExportHeader  = []string{"short_code", "url", "is_custom", "created_at", "expires_at"}
+	clickExportHeader = []string{"short_code", "ip", "ip_hash", "user_agent", "device", "os", "browser", "browser_version", "referrer", "country", "region", "is_bot", "is_repeat", "clicked_at"}
+)
+
+type linkExportRow struct {
+	ShortCode string     `json:"short_code"`
+	URL       string     `json:"url"`
+	IsCustom  bool       `json:"is_custom"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (row linkExportRow) record() []string {
+	expiresAt := ""
+	if row.ExpiresAt != nil {
+		expiresAt = row.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		row.ShortCode,
+		row.URL,
+		strconv.FormatBool(row.IsCustom),
+		row.CreatedAt.UTC().Format(time.RFC3339),
+		expiresAt,
+	}
+}
+
+type clickExportRow struct {
+	ShortCode      string    `json:"short_code"`
+	IP             string    `json:"ip"`
+	IPHash         string    `json:"ip_hash"`
+	UserAgent      string    `json:"user_agent"`
+	Device         string    `json:"device"`
+	OS             string    `json:"os"`
+	Browser        string    `json:"browser"`
+	BrowserVersion string    `json:"browser_version"`
+	Referrer       string    `json:"referrer"`
+	Country        string    `json:"country"`
+	Region         string    `json:"region"`
+	IsBot          bool      `json:"is_bot"`
+	IsRepeat       bool      `json:"is_repeat"`
+	ClickedAt      time.Time `json:"clicked_at"`
+}
+
+func (row clickExportRow) record() []string {
+	return []string{
+		row.ShortCode,
+		row.IP,
+		row.IPHash,
+		row.UserAgent,
+		row.Device,
+		row.OS,
+		row.Browser,
+		row.BrowserVersion,
+		row.Referrer,
+		row.Country,
+		row.Region,
+		strconv.FormatBool(row.IsBot),
+		strconv.FormatBool(row.IsRepeat),
+		row.ClickedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// exportWriter writes one row as a CSV record or as a JSON line.
+type exportWriter func(record []string, v any) error
+
+// @Summary Export links
+// @Description Streams all links as CSV (same header as import) or NDJSON
+// @Tags links
+// @Produce plain
+// @Param format query string false "Output format" Enums(csv, ndjson) default(csv)
+// @Success 200 {string} string "links"
+// @Failure 400 {object} response.Error
+// @Router /v1/export/links [get]
+func (r *V1) exportLinks(ctx *fiber.Ctx) error {
+	format := ctx.Query("format", _exportFormatCSV)
+
+	return r.streamExport(ctx, format, "links", linkExportHeader, func(c context.Context, write exportWriter) error {
+		return r.lk.ExportLinks(c, func(link entity.Link) error {
+			row := linkExportRow{
+				ShortCode: link.ShortCode,
+				URL:       link.URL,
+				IsCustom:  link.IsCustom,
+				CreatedAt: link.CreatedAt,
+				ExpiresAt: link.ExpiresAt,
+			}
+
+			return write(row.record(), row)
+		})
+	})
+}
+
+// @Summary Export clicks
+// @Description Streams raw clicks of the short link ordered by time as CSV or NDJSON.
+// @Description from/to accept RFC3339 or YYYY-MM-DD, a date-only "to" includes the whole day.
+// @Tags analytics
+// @Produce plain
+// @Param short path string true "Short code"
+// @Param format query string false "Output format" Enums(csv, ndjson) default(csv)
+// @Param from query string false "Start of the range (inclusive)"
+// @Param to query string false "End of the range (exclusive)"
+// @Param tz query string false "IANA time zone for date-only from/to" default(UTC)
+// @Param include_bots query bool false "Export clicks of bots and link previews too" default(true)
+// @Success 200 {string} string "clicks"
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /v1/links/{short}/clicks/export [get]
+func (r *V1) exportClicks(ctx *fiber.Ctx) error {
+	shortCode := ctx.Params("short")
+	format := ctx.Query("format", _exportFormatCSV)
+
+	filter, err := parseAnalyticsFilter(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	// a raw export keeps every click unless bots are excluded explicitly
+	if ctx.Query("include_bots") == "" {
+		filter.IncludeBots = true
+	}
+
+	err = r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
+		}
+		r.l.Error(err, "restapi - v1 - exportClicks")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	name := "clicks-" + shortCode
+
+	return r.streamExport(ctx, format, name, clickExportHeader, func(c context.Context, write exportWriter) error {
+		return r.lk.ExportClicks(c, shortCode, filter, func(click entity.Click) error {
+			row := clickExportRow{
+				ShortCode:      click.ShortCode,
+				IP:             click.IP,
+				IPHash:         click.IPHash,
+				UserAgent:      click.UserAgent,
+				Device:         click.Device,
+				OS:             click.OS,
+				Browser:        click.Browser,
+				BrowserVersion: click.BrowserVersion,
+				Referrer:       click.Referrer,
+				Country:        click.Country,
+				Region:         click.Region,
+				IsBot:          click.IsBot,
+				IsRepeat:       click.IsRepeat,
+				ClickedAt:      click.ClickedAt,
+			}
+
+			return write(row.record(), row)
+		})
+	})
+}
+
+// streamExport sets the response up as an attachment and streams rows produced by produce,
+// so the export is never held in memory.
+// Once streaming has started the status can't change: errors are logged and the body is cut short.
+func (r *V1) streamExport(ctx *fiber.Ctx, format, name string, header []string,
+	produce func(c context.Context, write exportWriter) error,
+) error {
+	switch format {
+	case _exportFormatCSV:
+		ctx.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	case _exportFormatNDJSON:
+		ctx.Set(fiber.HeaderContentType, "application/x-ndjson")
+	default:
+		return errorResponse(ctx, http.StatusBadRequest, "invalid format: must be csv or ndjson")
+	}
+
+	ctx.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+
+	c, cancel := context.WithCancel(context.Background())
+
+	streamBody(ctx, c, cancel, _exportWriteTimeout, func(c context.Context, w *bufio.Writer, flushBody func() error) {
+		var (
+			cw   *csv.Writer
+			enc  *json.Encoder
+			rows int
+		)
+
+		if format == _exportFormatCSV {
+			cw = csv.NewWriter(w)
+			_ = cw.Write(header)
+		} else {
+			enc = json.NewEncoder(w)
+		}
+
+		flush := func() error {
+			if cw != nil {
+				cw.Flush()
+				if err := cw.Error(); err != nil {
+					return err
+				}
+			}
+
+			return flushBody()
+		}
+
+		write := func(record []string, v any) error {
+			var err error
+			if cw != nil {
+				err = cw.Write(record)
+			} else {
+				err = enc.Encode(v)
+			}
+			if err != nil {
+				return err
+			}
+
+			rows++
+			if rows%_exportFlushEvery == 0 {
+				return flush()
+			}
+
+			return nil
+		}
+
+		err := produce(c, write)
+		if err != nil {
+			r.l.Error(err, "restapi - v1 - streamExport")
+		}
+
+		err = flush()
+		if err != nil {
+			r.l.Warn("restapi - v1 - streamExport - flush: %s", err)
+		}
+	})
+
+	return nil
+}
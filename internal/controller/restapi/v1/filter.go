@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/gofiber/fiber/v2"
+)
+
+const _dateLayout = "2006-01-02"
+
+// parseAnalyticsFilter reads optional tz (IANA name) and from/to query params as RFC3339 or YYYY-MM-DD.
+// Dates without time are taken in tz, a date-only "to" is moved to the start of the next day
+// so the whole day is included. include_bots adds bot clicks, they are left out by default.
+func parseAnalyticsFilter(ctx *fiber.Ctx) (entity.AnalyticsFilter, error) {
+	filter := entity.AnalyticsFilter{Location: time.UTC}
+
+	if v := ctx.Query("tz"); v != "" {
+		// "Local" is the server zone and unknown to postgres
+		loc, err := time.LoadLocation(v)
+		if err != nil || v == "Local" {
+			return entity.AnalyticsFilter{}, errors.New("invalid tz: use IANA time zone name, e.g. Europe/Moscow")
+		}
+		filter.Location = loc
+	}
+
+	if v := ctx.Query("from"); v != "" {
+		t, _, err := parseTimeParam(v, filter.Location)
+		if err != nil {
+			return entity.AnalyticsFilter{}, errors.New("invalid from: use RFC3339 or YYYY-MM-DD")
+		}
+		filter.From = &t
+	}
+
+	if v := ctx.Query("to"); v != "" {
+		t, dateOnly, err := parseTimeParam(v, filter.Location)
+		if err != nil {
+			return entity.AnalyticsFilter{}, errors.New("invalid to: use RFC3339 or YYYY-MM-DD")
+		}
+		if dateOnly {
+			t = t.AddDate(0, 0, 1)
+		}
+		filter.To = &t
+	}
+
+	if v := ctx.Query("include_bots"); v != "" {
+		includeBots, err := strconv.ParseBool(v)
+		if err != nil {
+			return entity.AnalyticsFilter{}, errors.New("invalid include_bots: use true or false")
+		}
+		filter.IncludeBots = includeBots
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return entity.AnalyticsFilter{}, errors.New("invalid range: from must be before to")
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(v string, loc *time.Location) (t time.Time, dateOnly bool, err error) {
+	t, err = time.Parse(time.RFC3339, v)
+	if err == nil {
+		return t, false, nil
+	}
+
+	t, err = time.ParseInLocation(_dateLayout, v, loc)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return t, true, nil
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Import links
+// @Description Imports code -> URL mappings from CSV (header: short_code,url[,created_at][,expires_at]) or NDJSON.
+// @Description Codes are kept as custom aliases, taken codes are reported as conflicts. For big files use cmd/import.
+// @Tags links
+// @Accept plain
+// @Produce json
+// @Param format query string true "File format" Enums(csv, ndjson)
+// @Param dry_run query bool false "Validate and check conflicts without writing"
+// @Param file body string true "File content"
+// @Success 200 {object} response.ImportLinksResponse
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /v1/import/links [post]
+func (r *V1) importLinks(ctx *fiber.Ctx) error {
+	format := ctx.Query("format")
+	dryRun := ctx.QueryBool("dry_run", false)
+
+	report, err := r.im.Import(ctx.UserContext(), bytes.NewReader(ctx.Body()), format, dryRun)
+	if err != nil {
+		if errors.Is(err, errs.ErrInvalidFormat) {
+			return errorResponse(ctx, http.StatusBadRequest, "invalid format: use csv with short_code,url header or ndjson")
+		}
+		r.l.Error(err, "restapi - v1 - importLinks")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.ImportLinksResponse{
+		Report: report,
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
@@ -4,15 +4,19 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/request"
 	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/response"
-	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/validate"
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
 	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/andreyxaxa/URL-Shortener/pkg/validate"
 	"github.com/gofiber/fiber/v2"
 )
 
-type analyticsHandler func(ctx *fiber.Ctx) error
+const _maxBatchSize = 1000
+
+type analyticsHandler func(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error
 
 // @Summary Create short URL
 // @Description Creates new short URL from original URL
@@ -32,36 +36,149 @@ func (r *V1) createShortURL(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusBadRequest, "invalid url")
 	}
 
+	expiresAt, err := validateCreateShortURL(body)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	shortCode, err := r.lk.CreateShortURL(ctx.UserContext(), body.URL, body.CustomAlias, expiresAt)
+	if err != nil {
+		if errors.Is(err, errs.ErrAliasAlreadyTaken) {
+			return errorResponse(ctx, http.StatusBadRequest, "alias already taken")
+		}
+		r.l.Error(err, "restapi - v1 - createShortURL")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.CreateShortURLResponse{
+		URL:       body.URL,
+		ShortURL:  fmt.Sprintf("%s/s/%s", r.baseURL, shortCode),
+		ExpiresAt: expiresAt,
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// @Summary Create short URLs in bulk
+// @Description Creates up to 1000 short URLs. Every item is validated and created independently,
+// @Description failed items are reported with an error and don't affect the rest of the batch.
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param request body []request.CreateShortURLRequest true "Links"
+// @Success 200 {object} response.CreateShortURLsResponse
+// @Failure 400 {object} response.Error
+// @Router /v1/shorten/batch [post]
+func (r *V1) createShortURLs(ctx *fiber.Ctx) error {
+	var body []request.CreateShortURLRequest
+
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	if len(body) == 0 || len(body) > _maxBatchSize {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid batch size: must be 1-1000 items")
+	}
+
+	resp := response.CreateShortURLsResponse{
+		Results: make([]response.CreateShortURLResult, len(body)),
+	}
+
+	// only valid items go to the use case, positions map them back to the request
+	links := make([]entity.Link, 0, len(body))
+	positions := make([]int, 0, len(body))
+
+	for i, item := range body {
+		resp.Results[i].URL = item.URL
+
+		expiresAt, err := validateCreateShortURL(item)
+		if err != nil {
+			resp.Results[i].Error = err.Error()
+
+			continue
+		}
+
+		links = append(links, entity.Link{
+			URL:       item.URL,
+			ShortCode: item.CustomAlias,
+			ExpiresAt: expiresAt,
+		})
+		positions = append(positions, i)
+	}
+
+	for j, res := range r.lk.CreateShortURLs(ctx.UserContext(), links) {
+		item := &resp.Results[positions[j]]
+
+		if res.Err != nil {
+			if errors.Is(res.Err, errs.ErrAliasAlreadyTaken) {
+				item.Error = "alias already taken"
+
+				continue
+			}
+			r.l.Error(res.Err, "restapi - v1 - createShortURLs")
+
+			item.Error = "storage problems"
+
+			continue
+		}
+
+		item.ShortURL = fmt.Sprintf("%s/s/%s", r.baseURL, res.Link.ShortCode)
+		item.ExpiresAt = res.Link.ExpiresAt
+	}
+
+	for _, item := range resp.Results {
+		if item.Error != "" {
+			resp.Failed++
+		} else {
+			resp.Created++
+		}
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// validateCreateShortURL checks the request and returns the resolved expiration time.
+func validateCreateShortURL(body request.CreateShortURLRequest) (*time.Time, error) {
 	if !validate.IsValidURL(body.URL) {
-		return errorResponse(ctx, http.StatusBadRequest, "invalid url")
+		return nil, errors.New("invalid url")
 	}
 
 	if body.CustomAlias != "" {
 		if !validate.IsValidAlias(body.CustomAlias) {
-			return errorResponse(ctx, http.StatusBadRequest, "invalid alias: use only letters, numbers, dash, underscope")
+			return nil, errors.New("invalid alias: use only letters, numbers, dash, underscope")
 		}
 
 		if !validate.IsValidAliasLength(body.CustomAlias) {
-			return errorResponse(ctx, http.StatusBadRequest, "invalid alias length: must be 3-50 chars")
+			return nil, errors.New("invalid alias length: must be 3-50 chars")
 		}
 	}
 
-	shortCode, err := r.lk.CreateShortURL(ctx.UserContext(), body.URL, body.CustomAlias)
-	if err != nil {
-		if errors.Is(err, errs.ErrAliasAlreadyTaken) {
-			return errorResponse(ctx, http.StatusBadRequest, "alias already taken")
+	return resolveExpiry(body.ExpiresAt, body.TTLSeconds)
+}
+
+// resolveExpiry turns expires_at / ttl_seconds into an absolute expiration time, nil means "never".
+func resolveExpiry(expiresAt *time.Time, ttlSeconds int64) (*time.Time, error) {
+	if expiresAt != nil && ttlSeconds != 0 {
+		return nil, errors.New("use either expires_at or ttl_seconds")
+	}
+
+	if ttlSeconds != 0 {
+		if !validate.IsValidTTL(ttlSeconds) {
+			return nil, errors.New("invalid ttl_seconds: must be positive and at most 10 years")
 		}
-		r.l.Error(err, "restapi - v1 - createShortURL")
 
-		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+		t := time.Now().Add(time.Duration(ttlSeconds) * time.Second)
+
+		return &t, nil
 	}
 
-	resp := response.CreateShortURLResponse{
-		URL:      body.URL,
-		ShortURL: fmt.Sprintf("%s/s/%s", r.baseURL, shortCode),
+	if expiresAt != nil && !validate.IsFutureTime(*expiresAt) {
+		return nil, errors.New("invalid expires_at: must be in the future")
 	}
 
-	return ctx.Status(http.StatusOK).JSON(resp)
+	return expiresAt, nil
 }
 
 // @Summary Redirect
@@ -71,6 +188,7 @@ func (r *V1) createShortURL(ctx *fiber.Ctx) error {
 // @Param short path string true "Short Code"
 // @Success 301 "Redirected"
 // @Failure 404 {object} response.Error
+// @Failure 410 {object} response.Error
 // @Failure 500 {object} response.Error
 // @Router /v1/s/{short} [get]
 func (r *V1) redirectToOriginalURL(ctx *fiber.Ctx) error {
@@ -81,19 +199,25 @@ func (r *V1) redirectToOriginalURL(ctx *fiber.Ctx) error {
 		if errors.Is(err, errs.ErrRecordNotFound) {
 			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
 		}
+		if errors.Is(err, errs.ErrLinkExpired) {
+			return errorResponse(ctx, http.StatusGone, "link expired")
+		}
 		r.l.Error(err, "restapi - v1 - redirectToOriginalURL")
 
 		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
 	}
 
-	err = r.lk.TrackClick(ctx.UserContext(), shortCode, ctx.IP(), ctx.Get("User-Agent"))
-	if err != nil {
-		if errors.Is(err, errs.ErrRecordNotFound) {
-			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
-		}
-		r.l.Error(err, "restapi - v1 - redirectToOriginalURL")
-
-		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	// click tracking must not affect the redirect
+	err = r.lk.TrackClick(ctx.UserContext(), entity.Click{
+		ShortCode:  shortCode,
+		IP:         ctx.IP(),
+		UserAgent:  ctx.Get(fiber.HeaderUserAgent),
+		Referrer:   ctx.Get(fiber.HeaderReferer),
+		DoNotTrack: ctx.Get("DNT") == "1" || ctx.Get("Sec-GPC") == "1",
+	})
+	// dropped clicks are counted in the click queue stats, logging each one would flood the log under load
+	if err != nil && !errors.Is(err, errs.ErrClickQueueFull) {
+		r.l.Warn("restapi - v1 - redirectToOriginalURL - r.lk.TrackClick: %v", err)
 	}
 
 	return ctx.Redirect(originalURL, http.StatusFound)
@@ -105,11 +229,19 @@ func (r *V1) redirectToOriginalURL(ctx *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param short path string true "Short Code"
-// @Param group-by query string false "Group critery" Enums(day, month, device, browser)
+// @Param group-by query string false "Group critery" Enums(hour, day, week, month, device, os, browser, referrer, country, bot)
+// @Param from query string false "Start of the range, RFC3339 or YYYY-MM-DD (inclusive)"
+// @Param to query string false "End of the range, RFC3339 or YYYY-MM-DD (exclusive, a date includes the whole day)"
+// @Param tz query string false "IANA time zone for day/month buckets and dates in from/to" default(UTC)
+// @Param include_bots query bool false "Count clicks of bots and link previews" default(false)
 // @Success 200 {object} response.GetAnalyticsResponse "Full analytics"
-// @Success 201 {object} response.GetAnalyticsByDateResponse "Analytics by date (group-by=day/month)"
+// @Success 201 {object} response.GetAnalyticsByDateResponse "Analytics by date (group-by=hour/day/week/month), empty buckets have zero clicks"
 // @Success 202 {object} response.GetAnalyticsByBrowserResponse "Analytics by browser (group-by=browser)"
 // @Success 203 {object} response.GetAnalyticsByDeviceResponse "Analytics by device (group-by=device)"
+// @Success 204 {object} response.GetAnalyticsByReferrerResponse "Analytics by referring host (group-by=referrer), empty host is direct traffic"
+// @Success 205 {object} response.GetAnalyticsByCountryResponse "Analytics by ISO country code (group-by=country), empty when unknown"
+// @Success 206 {object} response.GetAnalyticsByBotResponse "Human and bot clicks (group-by=bot), bots are always counted"
+// @Success 207 {object} response.GetAnalyticsByOSResponse "Analytics by operating system (group-by=os)"
 // @Failure 400 {object} response.Error
 // @Failure 404 {object} response.Error
 // @Failure 500 {object} response.Error
@@ -118,11 +250,17 @@ func (r *V1) getAnalytics(ctx *fiber.Ctx) error {
 	groupBy := ctx.Query("group-by")
 
 	strats := map[string]analyticsHandler{
-		"":        r.getFullAnalytics,
-		"day":     r.getAnalyticsByDate,
-		"month":   r.getAnalyticsByDate,
-		"device":  r.getAnalyticsByDevice,
-		"browser": r.getAnalyticsByBrowser,
+		"":         r.getFullAnalytics,
+		"hour":     r.getAnalyticsByDate,
+		"day":      r.getAnalyticsByDate,
+		"week":     r.getAnalyticsByDate,
+		"month":    r.getAnalyticsByDate,
+		"device":   r.getAnalyticsByDevice,
+		"os":       r.getAnalyticsByOS,
+		"browser":  r.getAnalyticsByBrowser,
+		"referrer": r.getAnalyticsByReferrer,
+		"country":  r.getAnalyticsByCountry,
+		"bot":      r.getAnalyticsByBot,
 	}
 
 	handler, ok := strats[groupBy]
@@ -130,10 +268,15 @@ func (r *V1) getAnalytics(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusBadRequest, "invalid group-by")
 	}
 
-	return handler(ctx)
+	filter, err := parseAnalyticsFilter(ctx)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	return handler(ctx, filter)
 }
 
-func (r *V1) getFullAnalytics(ctx *fiber.Ctx) error {
+func (r *V1) getFullAnalytics(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
 	shortCode := ctx.Params("short")
 
 	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
@@ -146,7 +289,7 @@ func (r *V1) getFullAnalytics(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
 	}
 
-	fullAnalytics, err := r.lk.GetAnalytics(ctx.UserContext(), shortCode)
+	fullAnalytics, err := r.lk.GetAnalytics(ctx.UserContext(), shortCode, filter)
 	if err != nil {
 		r.l.Error(err, "restapi - v1 - getAnalytics")
 
@@ -154,10 +297,16 @@ func (r *V1) getFullAnalytics(ctx *fiber.Ctx) error {
 	}
 
 	analytics := response.Analytics{
-		TotalClicks:     fullAnalytics.TotalClicks,
-		ClicksByBrowser: fullAnalytics.ClicksByBrowser,
-		ClicksByDevice:  fullAnalytics.ClicksByDevice,
-		RecentClicks:    make([]response.ClickByDate, 0),
+		TotalClicks:        fullAnalytics.TotalClicks,
+		DeduplicatedClicks: fullAnalytics.DeduplicatedClicks,
+		UniqueVisitors:     fullAnalytics.UniqueVisitors,
+		ClicksByBrowser:    fullAnalytics.ClicksByBrowser,
+		ClicksByDevice:     fullAnalytics.ClicksByDevice,
+		ClicksByOS:         fullAnalytics.ClicksByOS,
+		ClicksByReferrer:   fullAnalytics.ClicksByReferrer,
+		ClicksByCountry:    fullAnalytics.ClicksByCountry,
+		ClicksByBot:        fullAnalytics.ClicksByBot,
+		RecentClicks:       make([]response.ClickByDate, 0),
 	}
 
 	resp := response.GetAnalyticsResponse{
@@ -166,15 +315,16 @@ func (r *V1) getFullAnalytics(ctx *fiber.Ctx) error {
 
 	for _, a := range fullAnalytics.RecentClicks {
 		resp.Analytics.RecentClicks = append(resp.Analytics.RecentClicks, response.ClickByDate{
-			Date:   a.Date.Format("2006-01-02"),
-			Clicks: a.Clicks,
+			Date:           a.Date.In(filter.TZ()).Format("2006-01-02"),
+			Clicks:         a.Clicks,
+			UniqueVisitors: a.UniqueVisitors,
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(resp)
 }
 
-func (r *V1) getAnalyticsByDate(ctx *fiber.Ctx) error {
+func (r *V1) getAnalyticsByDate(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
 	shortCode := ctx.Params("short")
 	groupBy := ctx.Query("group-by")
 
@@ -188,18 +338,22 @@ func (r *V1) getAnalyticsByDate(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
 	}
 
-	recentClicks, err := r.lk.GetRecentClicks(ctx.UserContext(), shortCode, groupBy)
+	recentClicks, err := r.lk.GetRecentClicks(ctx.UserContext(), shortCode, groupBy, filter)
 	if err != nil {
 		if errors.Is(err, errs.ErrInvalidInterval) {
-			return errorResponse(ctx, http.StatusBadRequest, "invalid interval: must be \"day\" or \"month\"")
+			return errorResponse(ctx, http.StatusBadRequest, "invalid interval: must be \"hour\", \"day\", \"week\" or \"month\"")
 		}
 		r.l.Error(err, "restapi - v1 - getAnalyticsByDate")
 
 		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
 	}
 
+	// week buckets are labelled by their Monday
 	format := "2006-01-02"
-	if groupBy == "month" {
+	switch groupBy {
+	case "hour":
+		format = "2006-01-02T15:00"
+	case "month":
 		format = "2006-01"
 	}
 
@@ -212,15 +366,16 @@ func (r *V1) getAnalyticsByDate(ctx *fiber.Ctx) error {
 
 	for _, a := range recentClicks {
 		resp.Analytics.RecentClicks = append(resp.Analytics.RecentClicks, response.ClickByDate{
-			Date:   a.Date.Format(format),
-			Clicks: a.Clicks,
+			Date:           a.Date.In(filter.TZ()).Format(format),
+			Clicks:         a.Clicks,
+			UniqueVisitors: a.UniqueVisitors,
 		})
 	}
 
 	return ctx.Status(http.StatusOK).JSON(resp)
 }
 
-func (r *V1) getAnalyticsByBrowser(ctx *fiber.Ctx) error {
+func (r *V1) getAnalyticsByBrowser(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
 	shortCode := ctx.Params("short")
 
 	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
@@ -233,7 +388,7 @@ func (r *V1) getAnalyticsByBrowser(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
 	}
 
-	clicksByBrowser, err := r.lk.GetClicksByBrowser(ctx.UserContext(), shortCode)
+	clicksByBrowser, err := r.lk.GetClicksByBrowser(ctx.UserContext(), shortCode, filter)
 	if err != nil {
 		r.l.Error(err, "restapi - v1 - getAnalyticsByBrowser")
 
@@ -247,7 +402,7 @@ func (r *V1) getAnalyticsByBrowser(ctx *fiber.Ctx) error {
 	return ctx.Status(http.StatusOK).JSON(resp)
 }
 
-func (r *V1) getAnalyticsByDevice(ctx *fiber.Ctx) error {
+func (r *V1) getAnalyticsByDevice(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
 	shortCode := ctx.Params("short")
 
 	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
@@ -260,7 +415,7 @@ func (r *V1) getAnalyticsByDevice(ctx *fiber.Ctx) error {
 		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
 	}
 
-	clicksByDevice, err := r.lk.GetClicksByDevice(ctx.UserContext(), shortCode)
+	clicksByDevice, err := r.lk.GetClicksByDevice(ctx.UserContext(), shortCode, filter)
 	if err != nil {
 		r.l.Error(err, "restapi - v1 - getAnalyticsByDevice")
 
@@ -273,3 +428,111 @@ func (r *V1) getAnalyticsByDevice(ctx *fiber.Ctx) error {
 
 	return ctx.Status(http.StatusOK).JSON(resp)
 }
+
+func (r *V1) getAnalyticsByReferrer(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
+	shortCode := ctx.Params("short")
+
+	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
+		}
+		r.l.Error(err, "restapi - v1 - getAnalyticsByReferrer")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	clicksByReferrer, err := r.lk.GetClicksByReferrer(ctx.UserContext(), shortCode, filter)
+	if err != nil {
+		r.l.Error(err, "restapi - v1 - getAnalyticsByReferrer")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.GetAnalyticsByReferrerResponse{
+		Analytics: response.AnalyticsByReferrer{ClicksByReferrer: clicksByReferrer},
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+func (r *V1) getAnalyticsByCountry(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
+	shortCode := ctx.Params("short")
+
+	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
+		}
+		r.l.Error(err, "restapi - v1 - getAnalyticsByCountry")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	clicksByCountry, err := r.lk.GetClicksByCountry(ctx.UserContext(), shortCode, filter)
+	if err != nil {
+		r.l.Error(err, "restapi - v1 - getAnalyticsByCountry")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.GetAnalyticsByCountryResponse{
+		Analytics: response.AnalyticsByCountry{ClicksByCountry: clicksByCountry},
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+func (r *V1) getAnalyticsByBot(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
+	shortCode := ctx.Params("short")
+
+	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
+		}
+		r.l.Error(err, "restapi - v1 - getAnalyticsByBot")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	clicksByBot, err := r.lk.GetClicksByBot(ctx.UserContext(), shortCode, filter)
+	if err != nil {
+		r.l.Error(err, "restapi - v1 - getAnalyticsByBot")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.GetAnalyticsByBotResponse{
+		Analytics: response.AnalyticsByBot{ClicksByBot: clicksByBot},
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+func (r *V1) getAnalyticsByOS(ctx *fiber.Ctx, filter entity.AnalyticsFilter) error {
+	shortCode := ctx.Params("short")
+
+	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
+		}
+		r.l.Error(err, "restapi - v1 - getAnalyticsByOS")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	clicksByOS, err := r.lk.GetClicksByOS(ctx.UserContext(), shortCode, filter)
+	if err != nil {
+		r.l.Error(err, "restapi - v1 - getAnalyticsByOS")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.GetAnalyticsByOSResponse{
+		Analytics: response.AnalyticsByOS{ClicksByOS: clicksByOS},
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
@@ -0,0 +1,181 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/request"
+	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/response"
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/andreyxaxa/URL-Shortener/pkg/validate"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	_defaultLinksLimit = 20
+	_maxLinksLimit     = 100
+)
+
+// @Summary List links
+// @Description Returns links, newest first
+// @Tags links
+// @Produce json
+// @Param limit query int false "Page size (1-100)" default(20)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {object} response.ListLinksResponse
+// @Failure 400 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /v1/links [get]
+func (r *V1) listLinks(ctx *fiber.Ctx) error {
+	limit := ctx.QueryInt("limit", _defaultLinksLimit)
+	if limit < 1 || limit > _maxLinksLimit {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid limit: must be 1-100")
+	}
+
+	offset := ctx.QueryInt("offset", 0)
+	if offset < 0 {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid offset")
+	}
+
+	links, total, err := r.lk.ListLinks(ctx.UserContext(), limit, offset)
+	if err != nil {
+		r.l.Error(err, "restapi - v1 - listLinks")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.ListLinksResponse{
+		Links:  make([]response.Link, 0, len(links)),
+		Total:  total,
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	for _, link := range links {
+		resp.Links = append(resp.Links, r.linkResponse(link))
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// @Summary Get link
+// @Description Returns link metadata
+// @Tags links
+// @Produce json
+// @Param short path string true "Short Code"
+// @Success 200 {object} response.GetLinkResponse
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /v1/links/{short} [get]
+func (r *V1) getLink(ctx *fiber.Ctx) error {
+	shortCode := ctx.Params("short")
+
+	link, err := r.lk.GetLink(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find link")
+		}
+		r.l.Error(err, "restapi - v1 - getLink")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.GetLinkResponse{
+		Link: r.linkResponse(link),
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// @Summary Update link
+// @Description Changes destination and/or expiration of the link
+// @Tags links
+// @Accept json
+// @Produce json
+// @Param short path string true "Short Code"
+// @Param request body request.UpdateLinkRequest true "Changes"
+// @Success 200 {object} response.GetLinkResponse
+// @Failure 400 {object} response.Error
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /v1/links/{short} [patch]
+func (r *V1) updateLink(ctx *fiber.Ctx) error {
+	shortCode := ctx.Params("short")
+
+	var body request.UpdateLinkRequest
+
+	err := ctx.BodyParser(&body)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid request body")
+	}
+
+	if body.URL != nil && !validate.IsValidURL(*body.URL) {
+		return errorResponse(ctx, http.StatusBadRequest, "invalid url")
+	}
+
+	if body.NoExpiry && (body.ExpiresAt != nil || body.TTLSeconds != 0) {
+		return errorResponse(ctx, http.StatusBadRequest, "no_expiry cannot be combined with expires_at or ttl_seconds")
+	}
+
+	expiresAt, err := resolveExpiry(body.ExpiresAt, body.TTLSeconds)
+	if err != nil {
+		return errorResponse(ctx, http.StatusBadRequest, err.Error())
+	}
+
+	link, err := r.lk.UpdateLink(ctx.UserContext(), shortCode, entity.LinkUpdate{
+		URL:         body.URL,
+		ExpiresAt:   expiresAt,
+		ClearExpiry: body.NoExpiry,
+	})
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find link")
+		}
+		r.l.Error(err, "restapi - v1 - updateLink")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	resp := response.GetLinkResponse{
+		Link: r.linkResponse(link),
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
+
+// @Summary Delete link
+// @Description Deletes link with all its clicks
+// @Tags links
+// @Param short path string true "Short Code"
+// @Success 204 "Deleted"
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Router /v1/links/{short} [delete]
+func (r *V1) deleteLink(ctx *fiber.Ctx) error {
+	shortCode := ctx.Params("short")
+
+	err := r.lk.DeleteLink(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find link")
+		}
+		r.l.Error(err, "restapi - v1 - deleteLink")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	return ctx.SendStatus(http.StatusNoContent)
+}
+
+func (r *V1) linkResponse(link entity.Link) response.Link {
+	return response.Link{
+		ShortCode: link.ShortCode,
+		ShortURL:  fmt.Sprintf("%s/s/%s", r.baseURL, link.ShortCode),
+		URL:       link.URL,
+		IsCustom:  link.IsCustom,
+		CreatedAt: link.CreatedAt,
+		ExpiresAt: link.ExpiresAt,
+	}
+}
@@ -1,6 +1,10 @@
 package request
 
+import "time"
+
 type CreateShortURLRequest struct {
-	URL         string `json:"url"`
-	CustomAlias string `json:"custom_alias,omitempty"`
+	URL         string     `json:"url"`
+	CustomAlias string     `json:"custom_alias,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds  int64      `json:"ttl_seconds,omitempty"`
 }
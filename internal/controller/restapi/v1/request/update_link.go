@@ -0,0 +1,10 @@
+package request
+
+import "time"
+
+type UpdateLinkRequest struct {
+	URL        *string    `json:"url,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	TTLSeconds int64      `json:"ttl_seconds,omitempty"`
+	NoExpiry   bool       `json:"no_expiry,omitempty"`
+}
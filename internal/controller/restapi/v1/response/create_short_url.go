@@ -1,6 +1,9 @@
 package response
 
+import "time"
+
 type CreateShortURLResponse struct {
-	URL      string `json:"original_url"`
-	ShortURL string `json:"short_url"`
+	URL       string     `json:"original_url"`
+	ShortURL  string     `json:"short_url"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
@@ -0,0 +1,16 @@
+package response
+
+import "time"
+
+type CreateShortURLsResponse struct {
+	Created int                    `json:"created"`
+	Failed  int                    `json:"failed"`
+	Results []CreateShortURLResult `json:"results"`
+}
+
+type CreateShortURLResult struct {
+	URL       string     `json:"original_url"`
+	ShortURL  string     `json:"short_url,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
@@ -9,15 +9,22 @@ type GetAnalyticsResponse struct {
 }
 
 type Analytics struct {
-	TotalClicks     int64                   `json:"total_clicks"`
-	ClicksByBrowser []entity.ClickByBrowser `json:"clicks_by_browser"`
-	ClicksByDevice  []entity.ClickByDevice  `json:"clicks_by_device"`
-	RecentClicks    []ClickByDate           `json:"recent_clicks"`
+	TotalClicks        int64                    `json:"total_clicks"`
+	DeduplicatedClicks int64                    `json:"deduplicated_clicks"`
+	UniqueVisitors     int64                    `json:"unique_visitors"`
+	ClicksByBrowser    []entity.ClickByBrowser  `json:"clicks_by_browser"`
+	ClicksByDevice     []entity.ClickByDevice   `json:"clicks_by_device"`
+	ClicksByOS         []entity.ClickByOS       `json:"clicks_by_os"`
+	ClicksByReferrer   []entity.ClickByReferrer `json:"clicks_by_referrer"`
+	ClicksByCountry    []entity.ClickByCountry  `json:"clicks_by_country"`
+	ClicksByBot        []entity.ClickByBot      `json:"clicks_by_bot"`
+	RecentClicks       []ClickByDate            `json:"recent_clicks"`
 }
 
 type ClickByDate struct {
-	Date   string `json:"date"`
-	Clicks int64  `json:"clicks"`
+	Date           string `json:"date"`
+	Clicks         int64  `json:"clicks"`
+	UniqueVisitors *int64 `json:"unique_visitors,omitempty"`
 }
 
 // Analytics by day / month
@@ -49,3 +56,43 @@ type GetAnalyticsByDeviceResponse struct {
 type AnalyticsByDevice struct {
 	ClicksByDevice []entity.ClickByDevice `json:"clicks_by_device"`
 }
+
+// Analytics by referrer
+
+type GetAnalyticsByReferrerResponse struct {
+	Analytics AnalyticsByReferrer `json:"analytics"`
+}
+
+type AnalyticsByReferrer struct {
+	ClicksByReferrer []entity.ClickByReferrer `json:"clicks_by_referrer"`
+}
+
+// Analytics by country
+
+type GetAnalyticsByCountryResponse struct {
+	Analytics AnalyticsByCountry `json:"analytics"`
+}
+
+type AnalyticsByCountry struct {
+	ClicksByCountry []entity.ClickByCountry `json:"clicks_by_country"`
+}
+
+// Analytics by bot
+
+type GetAnalyticsByBotResponse struct {
+	Analytics AnalyticsByBot `json:"analytics"`
+}
+
+type AnalyticsByBot struct {
+	ClicksByBot []entity.ClickByBot `json:"clicks_by_bot"`
+}
+
+// Analytics by OS
+
+type GetAnalyticsByOSResponse struct {
+	Analytics AnalyticsByOS `json:"analytics"`
+}
+
+type AnalyticsByOS struct {
+	ClicksByOS []entity.ClickByOS `json:"clicks_by_os"`
+}
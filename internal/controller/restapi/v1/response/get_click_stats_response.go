@@ -0,0 +1,7 @@
+package response
+
+import "github.com/andreyxaxa/URL-Shortener/internal/entity"
+
+type GetClickStatsResponse struct {
+	Queue entity.ClickQueueStats `json:"queue"`
+}
@@ -0,0 +1,23 @@
+package response
+
+import "time"
+
+type Link struct {
+	ShortCode string     `json:"short_code"`
+	ShortURL  string     `json:"short_url"`
+	URL       string     `json:"original_url"`
+	IsCustom  bool       `json:"is_custom"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type GetLinkResponse struct {
+	Link Link `json:"link"`
+}
+
+type ListLinksResponse struct {
+	Links  []Link `json:"links"`
+	Total  int64  `json:"total"`
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset"`
+}
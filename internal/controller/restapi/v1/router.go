@@ -1,19 +1,36 @@
 package v1
 
 import (
+	"context"
+
 	"github.com/andreyxaxa/URL-Shortener/internal/usecase"
 	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
 	"github.com/gofiber/fiber/v2"
 )
 
-func NewLinkRoutes(apiV1Group fiber.Router, lk usecase.Link, l logger.Interface, baseURL string) {
-	r := &V1{lk: lk, l: l, baseURL: baseURL}
+func NewLinkRoutes(streams context.Context, apiV1Group fiber.Router, lk usecase.Link, im usecase.Importer, l logger.Interface,
+	baseURL string,
+) {
+	r := &V1{lk: lk, im: im, l: l, streams: streams, baseURL: baseURL}
 
 	{
 		// API
 		apiV1Group.Post("/shorten", r.createShortURL)
+		apiV1Group.Post("/shorten/batch", r.createShortURLs)
 		apiV1Group.Get("/s/:short", r.redirectToOriginalURL)
 		apiV1Group.Get("/analytics/:short", r.getAnalytics)
+		apiV1Group.Get("/analytics/:short/stream", r.streamClicks)
+		apiV1Group.Get("/stats/clicks", r.getClickStats)
+
+		// bulk routes stay out of /links/:short, "import" and "export" are valid short codes
+		apiV1Group.Post("/import/links", r.importLinks)
+		apiV1Group.Get("/export/links", r.exportLinks)
+
+		apiV1Group.Get("/links", r.listLinks)
+		apiV1Group.Get("/links/:short/clicks/export", r.exportClicks)
+		apiV1Group.Get("/links/:short", r.getLink)
+		apiV1Group.Patch("/links/:short", r.updateLink)
+		apiV1Group.Delete("/links/:short", r.deleteLink)
 
 		// Web
 		apiV1Group.Get("/web", r.showUI)
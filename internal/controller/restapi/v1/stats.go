@@ -0,0 +1,22 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/controller/restapi/v1/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Click queue stats
+// @Description Returns depth and counters of the click-tracking queue
+// @Tags stats
+// @Produce json
+// @Success 200 {object} response.GetClickStatsResponse
+// @Router /v1/stats/clicks [get]
+func (r *V1) getClickStats(ctx *fiber.Ctx) error {
+	resp := response.GetClickStatsResponse{
+		Queue: r.lk.GetClickQueueStats(),
+	}
+
+	return ctx.Status(http.StatusOK).JSON(resp)
+}
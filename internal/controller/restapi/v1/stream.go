@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// comments sent to idle streams, so proxies keep them open and gone clients are noticed
+	_streamHeartbeat    = 15 * time.Second
+	_streamWriteTimeout = 10 * time.Second
+	// _streamRetryAfter is suggested to clients rejected by the stream limit, in seconds
+	_streamRetryAfter = "30"
+)
+
+// @Summary Live clicks
+// @Description Pushes clicks of the short link as Server-Sent Events while they are tracked by any replica.
+// @Description Every click is a "click" event with a JSON body, "missed" counts events dropped before it
+// @Description because the client was reading too slowly. The stream is closed after a configured lifetime,
+// @Description EventSource clients reconnect on their own.
+// @Tags analytics
+// @Produce text/event-stream
+// @Param short path string true "Short code"
+// @Success 200 {object} entity.ClickEvent
+// @Failure 404 {object} response.Error
+// @Failure 500 {object} response.Error
+// @Failure 503 {object} response.Error
+// @Router /v1/analytics/{short}/stream [get]
+func (r *V1) streamClicks(ctx *fiber.Ctx) error {
+	shortCode := ctx.Params("short")
+
+	err := r.lk.ExistsByShortCode(ctx.UserContext(), shortCode)
+	if err != nil {
+		if errors.Is(err, errs.ErrRecordNotFound) {
+			return errorResponse(ctx, http.StatusNotFound, "couldnt find original URL")
+		}
+		r.l.Error(err, "restapi - v1 - streamClicks")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	// cancelling c unsubscribes, server shutdown cancels it too
+	c, cancel := context.WithCancel(r.streams)
+
+	events, err := r.lk.SubscribeClicks(c, shortCode)
+	if err != nil {
+		cancel()
+
+		if errors.Is(err, errs.ErrStreamLimit) {
+			ctx.Set(fiber.HeaderRetryAfter, _streamRetryAfter)
+
+			return errorResponse(ctx, http.StatusServiceUnavailable, "too many live streams")
+		}
+		r.l.Error(err, "restapi - v1 - streamClicks")
+
+		return errorResponse(ctx, http.StatusInternalServerError, "storage problems")
+	}
+
+	ctx.Set(fiber.HeaderContentType, "text/event-stream")
+	ctx.Set(fiber.HeaderCacheControl, "no-cache")
+	ctx.Set(fiber.HeaderConnection, "keep-alive")
+	// nginx buffers responses by default, events would arrive in bursts
+	ctx.Set("X-Accel-Buffering", "no")
+
+	streamBody(ctx, c, cancel, _streamWriteTimeout, func(_ context.Context, w *bufio.Writer, flush func() error) {
+		heartbeat := time.NewTicker(_streamHeartbeat)
+		defer heartbeat.Stop()
+
+		// send headers right away, clients consider the stream open only then
+		_, _ = w.WriteString(": connected\n\n")
+
+		for {
+			err := flush()
+			if err != nil {
+				return
+			}
+
+			select {
+			case event, ok := <-events:
+				if !ok {
+					// lifetime is over or the subscription is lost
+					return
+				}
+
+				data, err := json.Marshal(event)
+				if err != nil {
+					r.l.Error(err, "restapi - v1 - streamClicks - json.Marshal")
+
+					return
+				}
+
+				_, _ = fmt.Fprintf(w, "event: click\ndata: %s\n\n", data)
+			case <-heartbeat.C:
+				_, _ = w.WriteString(": ping\n\n")
+			}
+		}
+	})
+
+	return nil
+}
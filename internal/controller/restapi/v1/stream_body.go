@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"bufio"
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamBody makes write produce the response body after the handler has returned.
+// The request context is gone by then, so the stream runs under c, a context the handler derives
+// independently of the request to prepare the stream before responding; cancel is called once write returns.
+// The server write timeout is armed once per response, flush pushes it forward by timeout,
+// so long streams aren't cut.
+func streamBody(ctx *fiber.Ctx, c context.Context, cancel context.CancelFunc, timeout time.Duration,
+	write func(c context.Context, w *bufio.Writer, flush func() error),
+) {
+	conn := ctx.Context().Conn()
+
+	ctx.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		flush := func() error {
+			_ = conn.SetWriteDeadline(time.Now().Add(timeout))
+
+			return w.Flush()
+		}
+
+		write(c, w, flush)
+	})
+}
@@ -20,10 +20,16 @@ import "time"
 */
 
 type Analytics struct {
-	TotalClicks     int64            `json:"total_clicks"`
-	ClicksByBrowser []ClickByBrowser `json:"clicks_by_browser"`
-	ClicksByDevice  []ClickByDevice  `json:"clicks_by_device"`
-	RecentClicks    []ClickByDate    `json:"recent_clicks"`
+	TotalClicks        int64             `json:"total_clicks"`
+	DeduplicatedClicks int64             `json:"deduplicated_clicks"`
+	UniqueVisitors     int64             `json:"unique_visitors"`
+	ClicksByBrowser    []ClickByBrowser  `json:"clicks_by_browser"`
+	ClicksByDevice     []ClickByDevice   `json:"clicks_by_device"`
+	ClicksByOS         []ClickByOS       `json:"clicks_by_os"`
+	ClicksByReferrer   []ClickByReferrer `json:"clicks_by_referrer"`
+	ClicksByCountry    []ClickByCountry  `json:"clicks_by_country"`
+	ClicksByBot        []ClickByBot      `json:"clicks_by_bot"`
+	RecentClicks       []ClickByDate     `json:"recent_clicks"`
 }
 
 type ClickByDevice struct {
@@ -36,7 +42,50 @@ type ClickByBrowser struct {
 	Clicks  int64  `json:"clicks"`
 }
 
+// ClickByOS groups clicks by operating system family, empty when the user agent doesn't tell.
+type ClickByOS struct {
+	OS     string `json:"os"`
+	Clicks int64  `json:"clicks"`
+}
+
+// ClickByReferrer groups clicks by referring host, empty for direct visits.
+type ClickByReferrer struct {
+	Referrer string `json:"referrer"`
+	Clicks   int64  `json:"clicks"`
+}
+
+// ClickByCountry groups clicks by ISO country code, empty when the country is unknown.
+type ClickByCountry struct {
+	Country string `json:"country"`
+	Clicks  int64  `json:"clicks"`
+}
+
+// ClickByBot splits clicks into human and bot ones, bots are always counted here.
+type ClickByBot struct {
+	IsBot  bool  `json:"is_bot"`
+	Clicks int64 `json:"clicks"`
+}
+
 type ClickByDate struct {
 	Date   time.Time `json:"date"`
 	Clicks int64     `json:"clicks"`
+	// nil when the bucket can't be counted from daily visitor sets
+	UniqueVisitors *int64 `json:"unique_visitors,omitempty"`
+}
+
+// AnalyticsFilter narrows analytics to clicks in [From, To), nil bounds are open.
+// Date buckets are computed in Location, UTC if nil. Bot clicks are left out unless IncludeBots is set.
+type AnalyticsFilter struct {
+	From        *time.Time
+	To          *time.Time
+	Location    *time.Location
+	IncludeBots bool
+}
+
+func (f AnalyticsFilter) TZ() *time.Location {
+	if f.Location == nil {
+		return time.UTC
+	}
+
+	return f.Location
 }
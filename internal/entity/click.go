@@ -0,0 +1,53 @@
+package entity
+
+import "time"
+
+type Click struct {
+	URLID     int64  `json:"-"`
+	ShortCode string `json:"short_code"`
+	// IP is an address or, once anonymised, a network like 203.0.113.0/24; empty in hash mode,
+	// where IPHash holds the address hashed with a periodically rotated key
+	IP        string `json:"ip"`
+	IPHash    string `json:"ip_hash"`
+	UserAgent string `json:"user_agent"`
+	// VisitorID identifies the visitor for unique and repeat counts, empty when the visitor asked not to be tracked
+	VisitorID string `json:"-"`
+	// DoNotTrack is set by DNT or Sec-GPC request headers
+	DoNotTrack bool   `json:"-"`
+	Device     string `json:"device"`
+	Browser    string `json:"browser"`
+	// BrowserVersion is the major version of the browser
+	BrowserVersion string `json:"browser_version"`
+	OS             string `json:"os"`
+	// Referrer is the raw Referer header, ReferrerHost its normalised host, empty for direct visits
+	Referrer     string `json:"referrer"`
+	ReferrerHost string `json:"referrer_host"`
+	// Country and Region are ISO codes resolved from IP, empty when unknown or GeoIP is disabled
+	Country string `json:"country"`
+	Region  string `json:"region"`
+	IsBot   bool   `json:"is_bot"`
+	// IsRepeat marks a click repeated by the same visitor within the dedup window
+	IsRepeat  bool      `json:"is_repeat"`
+	ClickedAt time.Time `json:"clicked_at"`
+}
+
+// ClickEvent is a tracked click pushed to live streams, it carries no identifiers of the visitor
+type ClickEvent struct {
+	ShortCode string `json:"short_code"`
+	Browser   string `json:"browser"`
+	Device    string `json:"device"`
+	Country   string `json:"country,omitempty"`
+	IsBot     bool   `json:"is_bot"`
+	// Missed is the number of events dropped before this one because the subscriber was too slow
+	Missed    int64     `json:"missed,omitempty"`
+	ClickedAt time.Time `json:"clicked_at"`
+}
+
+type ClickQueueStats struct {
+	Depth     int   `json:"depth"`
+	Capacity  int   `json:"capacity"`
+	Enqueued  int64 `json:"enqueued"`
+	Dropped   int64 `json:"dropped"`
+	Processed int64 `json:"processed"`
+	Failed    int64 `json:"failed"`
+}
@@ -0,0 +1,17 @@
+package entity
+
+type ImportReport struct {
+	DryRun    bool          `json:"dry_run"`
+	Total     int           `json:"total"`
+	Imported  int           `json:"imported"`
+	Conflicts int           `json:"conflicts"`
+	Invalid   int           `json:"invalid"`
+	Failed    int           `json:"failed"`
+	Errors    []ImportError `json:"errors"`
+}
+
+type ImportError struct {
+	Line      int    `json:"line"`
+	ShortCode string `json:"short_code,omitempty"`
+	Error     string `json:"error"`
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+type Link struct {
+	ID        int64      `json:"id"`
+	URL       string     `json:"url"`
+	ShortCode string     `json:"short_code"`
+	IsCustom  bool       `json:"is_custom"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+func (l Link) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// LinkUpdate holds changes for a link, nil fields are left untouched.
+type LinkUpdate struct {
+	URL         *string
+	ExpiresAt   *time.Time
+	ClearExpiry bool
+}
+
+// LinkResult is the outcome of creating one link of a batch.
+type LinkResult struct {
+	Link Link
+	Err  error
+}
@@ -40,8 +40,12 @@ func (r *LinkCache) Set(ctx context.Context, key string, value string, ttl time.
 	return nil
 }
 
-func (r *LinkCache) Delete(ctx context.Context, key string) error {
-	err := r.c.Client.Del(ctx, key).Err()
+func (r *LinkCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := r.c.Client.Del(ctx, keys...).Err()
 	if err != nil {
 		return fmt.Errorf("LinkCache - Delete - r.c.Client.Del: %w", err)
 	}
@@ -90,3 +94,137 @@ func (r *LinkCache) GetInt(ctx context.Context, key string) (int64, error) {
 
 	return v, nil
 }
+
+// AddUnique adds members to the HyperLogLog at key, ttl 0 keeps the key without expiration.
+func (r *LinkCache) AddUnique(ctx context.Context, key string, ttl time.Duration, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, len(members))
+	for _, m := range members {
+		values = append(values, m)
+	}
+
+	pipe := r.c.Client.Pipeline()
+
+	pipe.PFAdd(ctx, key, values...)
+	if ttl > 0 {
+		pipe.Expire(ctx, key, ttl)
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("LinkCache - AddUnique - pipe.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// CountUnique returns the approximate cardinality of the union of HyperLogLogs for every group of keys,
+// in one round trip. Missing keys count as empty.
+func (r *LinkCache) CountUnique(ctx context.Context, groups ...[]string) ([]int64, error) {
+	pipe := r.c.Client.Pipeline()
+
+	cmds := make([]*redis.IntCmd, 0, len(groups))
+	for _, keys := range groups {
+		cmds = append(cmds, pipe.PFCount(ctx, keys...))
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("LinkCache - CountUnique - pipe.Exec: %w", err)
+	}
+
+	counts := make([]int64, 0, len(cmds))
+	for _, cmd := range cmds {
+		counts = append(counts, cmd.Val())
+	}
+
+	return counts, nil
+}
+
+// SetIfNotExists runs SET NX EX for every key in one round trip, commands run in order,
+// so a key repeated in keys is set only by its first occurrence.
+func (r *LinkCache) SetIfNotExists(ctx context.Context, ttl time.Duration, keys ...string) ([]bool, error) {
+	pipe := r.c.Client.Pipeline()
+
+	cmds := make([]*redis.BoolCmd, 0, len(keys))
+	for _, key := range keys {
+		cmds = append(cmds, pipe.SetNX(ctx, key, 1, ttl))
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("LinkCache - SetIfNotExists - pipe.Exec: %w", err)
+	}
+
+	set := make([]bool, 0, len(cmds))
+	for _, cmd := range cmds {
+		set = append(set, cmd.Val())
+	}
+
+	return set, nil
+}
+
+func (r *LinkCache) Publish(ctx context.Context, messages map[string][]string) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	pipe := r.c.Client.Pipeline()
+
+	for channel, msgs := range messages {
+		for _, msg := range msgs {
+			pipe.Publish(ctx, channel, msg)
+		}
+	}
+
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("LinkCache - Publish - pipe.Exec: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe holds a dedicated Redis connection until ctx is done. Messages are handed over one by one,
+// a reader that doesn't keep up stalls only its own subscription.
+func (r *LinkCache) Subscribe(ctx context.Context, channel string) (<-chan string, error) {
+	sub := r.c.Client.Subscribe(ctx, channel)
+
+	// wait for the confirmation, so an unreachable Redis fails the call and not the stream
+	_, err := sub.Receive(ctx)
+	if err != nil {
+		_ = sub.Close()
+
+		return nil, fmt.Errorf("LinkCache - Subscribe - sub.Receive: %w", err)
+	}
+
+	out := make(chan string)
+
+	go func() {
+		defer close(out)
+		defer sub.Close()
+
+		msgs := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -9,25 +9,77 @@ import (
 
 type (
 	LinkRepo interface {
-		GetNextSequenceValue(ctx context.Context) (int64, error)
-		CreateWithShortCode(ctx context.Context, ID int64, originalURL, shortCode string, isCustom bool) error
-		GetOriginalURLByShortCode(ctx context.Context, shortCode string) (string, error)
-		GetIDByShortCode(ctx context.Context, shortCode string) (int64, error)
-		CreateClick(ctx context.Context, urlID int64, IP, userAgent, device, browser string) error
-		GetAnalytics(ctx context.Context, shortCode string) (entity.Analytics, error)
-		GetRecentClicks(ctx context.Context, shortCode, interval string) ([]entity.ClickByDate, error)
-		GetClicksByBrowser(ctx context.Context, shortCode string) ([]entity.ClickByBrowser, error)
-		GetClicksByDevice(ctx context.Context, shortCode string) ([]entity.ClickByDevice, error)
+		// GetNextSequenceValues reserves n values of the urls id sequence in one round trip
+		GetNextSequenceValues(ctx context.Context, n int) ([]int64, error)
+		// CreateWithShortCode uses link.ID only for generated (non-custom) short codes
+		// and link.CreatedAt only if set. Returns errs.ErrAliasAlreadyTaken if the short code is already used
+		CreateWithShortCode(ctx context.Context, link entity.Link) error
+		// CreateBatch inserts links in one statement, skipping taken short codes. Like CreateWithShortCode
+		// it uses link.ID only for generated short codes. Returns ID and ShortCode of inserted links
+		CreateBatch(ctx context.Context, links []entity.Link) ([]entity.Link, error)
+		GetByShortCode(ctx context.Context, shortCode string) (entity.Link, error)
+		ListLinks(ctx context.Context, limit, offset int) ([]entity.Link, error)
+		CountLinks(ctx context.Context) (int64, error)
+		// UpdateLink updates url and expiration of the link with link.ShortCode
+		UpdateLink(ctx context.Context, link entity.Link) error
+		// DeleteByShortCode returns the ID of the deleted link
+		DeleteByShortCode(ctx context.Context, shortCode string) (int64, error)
+		// StreamLinks calls fn for every link without loading them all into memory, stops on fn error
+		StreamLinks(ctx context.Context, fn func(entity.Link) error) error
+		// StreamClicks calls fn for every click of the link in the filter range, ordered by time
+		StreamClicks(ctx context.Context, shortCode string, filter entity.AnalyticsFilter, fn func(entity.Click) error) error
+		// GetIDsByShortCodes returns IDs of existing links only, keyed by short code
+		GetIDsByShortCodes(ctx context.Context, shortCodes []string) (map[string]int64, error)
+		CreateClicks(ctx context.Context, clicks []entity.Click) (int64, error)
+		GetAnalytics(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) (entity.Analytics, error)
+		GetRecentClicks(ctx context.Context, shortCode, interval string, filter entity.AnalyticsFilter) ([]entity.ClickByDate, error)
+		GetClicksByBrowser(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBrowser, error)
+		GetClicksByDevice(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByDevice, error)
+		GetClicksByOS(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByOS, error)
+		GetClicksByReferrer(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByReferrer, error)
+		GetClicksByCountry(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByCountry, error)
+		GetClicksByBot(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBot, error)
 		// ExistsByShortCode returns error if record not exists, nil if record exists
 		ExistsByShortCode(ctx context.Context, shortCode string) error
+		// GetRollupWatermark returns the time before which clicks are counted in rollups,
+		// except clicks written late that wait for the next RollupClicks
+		GetRollupWatermark(ctx context.Context) (time.Time, error)
+		// RollupClicks adds clicks before upTo (truncated to the hour) that are not rolled up yet to rollups.
+		// Returns the new watermark
+		RollupClicks(ctx context.Context, upTo time.Time) (time.Time, error)
+		// GetAnonymizeWatermark returns the time before which clicks have no identifiers left
+		GetAnonymizeWatermark(ctx context.Context) (time.Time, error)
+		// AnonymizeClicks truncates IPs and removes IP hashes, user agents and referrers of clicks from the watermark
+		// up to upTo. Returns the new watermark
+		AnonymizeClicks(ctx context.Context, upTo time.Time, ipv4Bits, ipv6Bits int) (time.Time, error)
+		// CreateClickPartitions creates missing monthly partitions of clicks for months from..to.
+		// Returns names of created partitions
+		CreateClickPartitions(ctx context.Context, from, to time.Time) ([]string, error)
+		// DropClickPartitions drops monthly partitions of clicks that end not later than before
+		// and have no clicks waiting for a rollup.
+		// Returns names of dropped partitions
+		DropClickPartitions(ctx context.Context, before time.Time) ([]string, error)
+		// DeleteExpired deletes up to limit links expired before the given time and returns their IDs and short codes
+		DeleteExpired(ctx context.Context, before time.Time, limit int) ([]entity.Link, error)
 	}
 
 	LinkCache interface {
 		Get(ctx context.Context, key string) (string, error)
 		GetInt(ctx context.Context, key string) (int64, error)
 		Set(ctx context.Context, key string, value string, ttl time.Duration) error
-		Delete(ctx context.Context, key string) error
+		Delete(ctx context.Context, keys ...string) error
 		Increment(ctx context.Context, key string) (int64, error)
 		IncrementWithExpiry(ctx context.Context, key string, ttl time.Duration) (int64, error)
+		// AddUnique adds members to the HyperLogLog at key, ttl 0 means no expiration
+		AddUnique(ctx context.Context, key string, ttl time.Duration, members ...string) error
+		// CountUnique returns approximate unique counts of the union of keys for every group
+		CountUnique(ctx context.Context, groups ...[]string) ([]int64, error)
+		// SetIfNotExists sets keys that don't exist yet with ttl, in order.
+		// Returns for every key whether it was set by this call
+		SetIfNotExists(ctx context.Context, ttl time.Duration, keys ...string) ([]bool, error)
+		// Publish publishes messages keyed by channel in one round trip, in order within a channel
+		Publish(ctx context.Context, messages map[string][]string) error
+		// Subscribe delivers messages published to channel until ctx is done, then closes the returned channel
+		Subscribe(ctx context.Context, channel string) (<-chan string, error)
 	}
 )
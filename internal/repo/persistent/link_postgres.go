@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/Masterminds/squirrel"
@@ -11,6 +12,7 @@ import (
 	"github.com/andreyxaxa/URL-Shortener/pkg/postgres"
 	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 const (
@@ -24,13 +26,33 @@ const (
 	shortCodeColumn = "short_code"
 	isCustomColumn  = "is_custom"
 	createdAtColumn = "created_at"
-
-	urlIdColumn         = "url_id"
-	ipAddrColumn        = "ip_address"
-	userAgentColumn     = "user_agent"
-	deviceColumn        = "device"
-	browserFamilyColumn = "browser_family"
-	clickedAtColumn     = "clicked_at"
+	expiresAtColumn = "expires_at"
+
+	urlIdColumn          = "url_id"
+	ipAddrColumn         = "ip_address"
+	ipHashColumn         = "ip_hash"
+	userAgentColumn      = "user_agent"
+	deviceColumn         = "device"
+	browserFamilyColumn  = "browser_family"
+	browserVersionColumn = "browser_version"
+	osColumn             = "os"
+	clickedAtColumn      = "clicked_at"
+	referrerColumn       = "referrer"
+	referrerHostColumn   = "referrer_host"
+	countryColumn        = "country"
+	regionColumn         = "region"
+	isBotColumn          = "is_bot"
+	isRepeatColumn       = "is_repeat"
+
+	// Postgres error codes
+	uniqueViolationCode = "23505"
+
+	// Unique constraints on urls.short_code: the column constraint and the index
+	shortCodeKeyConstraint   = "urls_short_code_key"
+	shortCodeIndexConstraint = "idx_urls_short_code"
+
+	// how many date buckets analytics returns
+	recentClicksLimit = 90
 )
 
 type LinkRepo struct {
@@ -41,192 +63,568 @@ func New(pg *postgres.Postgres) *LinkRepo {
 	return &LinkRepo{pg}
 }
 
-func (r *LinkRepo) GetNextSequenceValue(ctx context.Context) (int64, error) {
+func (r *LinkRepo) GetNextSequenceValues(ctx context.Context, n int) ([]int64, error) {
+	sql := `
+	SELECT nextval('urls_id_seq')
+	FROM generate_series(1, $1);
+	`
+
+	rows, err := r.Pool.Query(ctx, sql, n)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetNextSequenceValues - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	IDs := make([]int64, 0, n)
+
+	for rows.Next() {
+		var ID int64
+		if err := rows.Scan(&ID); err != nil {
+			return nil, fmt.Errorf("LinkRepo - GetNextSequenceValues - rows.Scan: %w", err)
+		}
+		IDs = append(IDs, ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetNextSequenceValues - rows.Err: %w", err)
+	}
+
+	return IDs, nil
+}
+
+func (r *LinkRepo) CreateWithShortCode(ctx context.Context, link entity.Link) error {
+	columns := []string{urlColumn, shortCodeColumn, isCustomColumn, expiresAtColumn}
+	values := []any{link.URL, link.ShortCode, link.IsCustom, link.ExpiresAt}
+
+	// custom aliases take id from the sequence default
+	if !link.IsCustom {
+		columns = append(columns, idColumn)
+		values = append(values, link.ID)
+	}
+
+	// imported links keep their original creation date
+	if !link.CreatedAt.IsZero() {
+		columns = append(columns, createdAtColumn)
+		values = append(values, link.CreatedAt)
+	}
+
 	sql, args, err := r.Builder.
-		Select("nextval('urls_id_seq')").
+		Insert(urlsTable).
+		Columns(columns...).
+		Values(values...).
 		ToSql()
 	if err != nil {
-		return 0, fmt.Errorf("LinkRepo - GetNextSequenceValue - r.Builder.ToSql: %w", err)
+		return fmt.Errorf("LinkRepo - CreateWithShortCode - r.Builder.ToSql: %w", err)
 	}
 
-	var ID int64
-
-	row := r.Pool.QueryRow(ctx, sql, args...)
-	err = row.Scan(&ID)
+	_, err = r.Pool.Exec(ctx, sql, args...)
 	if err != nil {
-		return 0, fmt.Errorf("LinkRepo - GetNextSequenceValue - row.Scan: %w", err)
+		if isUniqueViolation(err, shortCodeKeyConstraint, shortCodeIndexConstraint) {
+			return fmt.Errorf("LinkRepo - CreateWithShortCode: %w", errs.ErrAliasAlreadyTaken)
+		}
+		return fmt.Errorf("LinkRepo - CreateWithShortCode - r.Pool.Exec: %w", err)
 	}
 
-	return ID, nil
+	return nil
 }
 
-func (r *LinkRepo) CreateWithShortCode(ctx context.Context, ID int64, originalURL, shortCode string, isCustom bool) error {
-	if isCustom {
-		sql, args, err := r.Builder.
-			Insert(urlsTable).
-			Columns(urlColumn, shortCodeColumn, isCustomColumn).
-			Values(originalURL, shortCode, isCustom).
-			ToSql()
-		if err != nil {
-			return fmt.Errorf("LinkRepo - CreateWithShortCode - r.Builder.ToSql: %w", err)
-		}
+func (r *LinkRepo) CreateBatch(ctx context.Context, links []entity.Link) ([]entity.Link, error) {
+	builder := r.Builder.
+		Insert(urlsTable).
+		Columns(idColumn, urlColumn, shortCodeColumn, isCustomColumn, expiresAtColumn)
 
-		_, err = r.Pool.Exec(ctx, sql, args...)
-		if err != nil {
-			return fmt.Errorf("LinkRepo - CreateWithShortCode - r.Pool.Exec: %w", err)
+	for _, link := range links {
+		var ID any = link.ID
+		// custom aliases take id from the sequence default
+		if link.IsCustom {
+			ID = squirrel.Expr("DEFAULT")
 		}
 
-	} else {
-		sql, args, err := r.Builder.
-			Insert(urlsTable).
-			Columns(idColumn, urlColumn, shortCodeColumn, isCustomColumn).
-			Values(ID, originalURL, shortCode, isCustom).
-			ToSql()
-		if err != nil {
-			return fmt.Errorf("LinkRepo - CreateWithShortCode - r.Builder.ToSql: %w", err)
-		}
+		builder = builder.Values(ID, link.URL, link.ShortCode, link.IsCustom, link.ExpiresAt)
+	}
 
-		_, err = r.Pool.Exec(ctx, sql, args...)
-		if err != nil {
-			return fmt.Errorf("LinkRepo - CreateWithShortCode - r.Pool.Exec: %w", err)
+	sql, args, err := builder.
+		Suffix("ON CONFLICT (" + shortCodeColumn + ") DO NOTHING RETURNING " + idColumn + ", " + shortCodeColumn).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - CreateBatch - r.Builder.ToSql: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - CreateBatch - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	inserted := make([]entity.Link, 0, len(links))
+
+	for rows.Next() {
+		var link entity.Link
+		if err := rows.Scan(&link.ID, &link.ShortCode); err != nil {
+			return nil, fmt.Errorf("LinkRepo - CreateBatch - rows.Scan: %w", err)
 		}
+		inserted = append(inserted, link)
 	}
 
-	return nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LinkRepo - CreateBatch - rows.Err: %w", err)
+	}
+
+	return inserted, nil
 }
 
-func (r *LinkRepo) GetOriginalURLByShortCode(ctx context.Context, shortCode string) (string, error) {
+func (r *LinkRepo) GetByShortCode(ctx context.Context, shortCode string) (entity.Link, error) {
 	sql, args, err := r.Builder.
-		Select(urlColumn).
+		Select(idColumn, urlColumn, shortCodeColumn, isCustomColumn, createdAtColumn, expiresAtColumn).
 		From(urlsTable).
 		Where(squirrel.Eq{shortCodeColumn: shortCode}).
 		ToSql()
 	if err != nil {
-		return "", fmt.Errorf("LinkRepo - GetOriginalURLByShortCode - r.Builder.ToSql: %w", err)
+		return entity.Link{}, fmt.Errorf("LinkRepo - GetByShortCode - r.Builder.ToSql: %w", err)
 	}
 
-	var url string
+	var link entity.Link
 
-	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&url)
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(
+		&link.ID,
+		&link.URL,
+		&link.ShortCode,
+		&link.IsCustom,
+		&link.CreatedAt,
+		&link.ExpiresAt,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", fmt.Errorf("LinkRepo - GetOriginalURLByShortCode: %w", errs.ErrRecordNotFound)
+			return entity.Link{}, fmt.Errorf("LinkRepo - GetByShortCode: %w", errs.ErrRecordNotFound)
 		}
-		return "", fmt.Errorf("LinkRepo - GetOriginalURLByShortCode - row.Scan: %w", err)
+		return entity.Link{}, fmt.Errorf("LinkRepo - GetByShortCode - row.Scan: %w", err)
 	}
 
-	return url, nil
+	return link, nil
 }
 
-func (r *LinkRepo) GetIDByShortCode(ctx context.Context, shortCode string) (int64, error) {
+func (r *LinkRepo) ListLinks(ctx context.Context, limit, offset int) ([]entity.Link, error) {
 	sql, args, err := r.Builder.
-		Select(idColumn).
+		Select(idColumn, urlColumn, shortCodeColumn, isCustomColumn, createdAtColumn, expiresAtColumn).
+		From(urlsTable).
+		OrderBy(idColumn + " DESC").
+		Limit(uint64(limit)).   //nolint:gosec
+		Offset(uint64(offset)). //nolint:gosec
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - ListLinks - r.Builder.ToSql: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - ListLinks - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]entity.Link, 0, limit)
+
+	for rows.Next() {
+		var link entity.Link
+		if err := rows.Scan(
+			&link.ID,
+			&link.URL,
+			&link.ShortCode,
+			&link.IsCustom,
+			&link.CreatedAt,
+			&link.ExpiresAt,
+		); err != nil {
+			return nil, fmt.Errorf("LinkRepo - ListLinks - rows.Scan: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LinkRepo - ListLinks - rows.Err: %w", err)
+	}
+
+	return links, nil
+}
+
+func (r *LinkRepo) CountLinks(ctx context.Context) (int64, error) {
+	sql, args, err := r.Builder.
+		Select("COUNT(*)").
 		From(urlsTable).
+		ToSql()
+	if err != nil {
+		return 0, fmt.Errorf("LinkRepo - CountLinks - r.Builder.ToSql: %w", err)
+	}
+
+	var total int64
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("LinkRepo - CountLinks - row.Scan: %w", err)
+	}
+
+	return total, nil
+}
+
+func (r *LinkRepo) UpdateLink(ctx context.Context, link entity.Link) error {
+	sql, args, err := r.Builder.
+		Update(urlsTable).
+		Set(urlColumn, link.URL).
+		Set(expiresAtColumn, link.ExpiresAt).
+		Where(squirrel.Eq{shortCodeColumn: link.ShortCode}).
+		ToSql()
+	if err != nil {
+		return fmt.Errorf("LinkRepo - UpdateLink - r.Builder.ToSql: %w", err)
+	}
+
+	tag, err := r.Pool.Exec(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("LinkRepo - UpdateLink - r.Pool.Exec: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("LinkRepo - UpdateLink: %w", errs.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (r *LinkRepo) DeleteByShortCode(ctx context.Context, shortCode string) (int64, error) {
+	sql, args, err := r.Builder.
+		Delete(urlsTable).
 		Where(squirrel.Eq{shortCodeColumn: shortCode}).
+		Suffix("RETURNING " + idColumn).
 		ToSql()
 	if err != nil {
-		return 0, fmt.Errorf("LinkRepo - GetIDByShortCode - r.Builder.ToSql: %w", err)
+		return 0, fmt.Errorf("LinkRepo - DeleteByShortCode - r.Builder.ToSql: %w", err)
 	}
 
 	var ID int64
 
-	row := r.Pool.QueryRow(ctx, sql, args...)
-	err = row.Scan(&ID)
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&ID)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, fmt.Errorf("LinkRepo - GetIDByShortCode: %w", errs.ErrRecordNotFound)
+			return 0, fmt.Errorf("LinkRepo - DeleteByShortCode: %w", errs.ErrRecordNotFound)
 		}
-		return 0, fmt.Errorf("LinkRepo - GetIDByShortCode - row.Scan: %w", err)
+		return 0, fmt.Errorf("LinkRepo - DeleteByShortCode - row.Scan: %w", err)
 	}
 
 	return ID, nil
 }
 
-func (r *LinkRepo) CreateClick(ctx context.Context, urlID int64, IP, userAgent, device, browser string) error {
+func (r *LinkRepo) StreamLinks(ctx context.Context, fn func(entity.Link) error) error {
 	sql, args, err := r.Builder.
-		Insert(clicksTable).
-		Columns(urlIdColumn, ipAddrColumn, userAgentColumn, browserFamilyColumn, deviceColumn, clickedAtColumn).
-		Values(urlID, IP, userAgent, browser, device, time.Now()).
+		Select(idColumn, urlColumn, shortCodeColumn, isCustomColumn, createdAtColumn, expiresAtColumn).
+		From(urlsTable).
+		OrderBy(idColumn).
 		ToSql()
 	if err != nil {
-		return fmt.Errorf("LinkRepo - CreateClick - r.Builder.ToSql: %w", err)
+		return fmt.Errorf("LinkRepo - StreamLinks - r.Builder.ToSql: %w", err)
 	}
 
-	_, err = r.Pool.Exec(ctx, sql, args...)
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("LinkRepo - StreamLinks - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var link entity.Link
+		if err := rows.Scan(
+			&link.ID,
+			&link.URL,
+			&link.ShortCode,
+			&link.IsCustom,
+			&link.CreatedAt,
+			&link.ExpiresAt,
+		); err != nil {
+			return fmt.Errorf("LinkRepo - StreamLinks - rows.Scan: %w", err)
+		}
+
+		if err := fn(link); err != nil {
+			return fmt.Errorf("LinkRepo - StreamLinks - fn: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("LinkRepo - StreamLinks - rows.Err: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LinkRepo) StreamClicks(ctx context.Context, shortCode string, filter entity.AnalyticsFilter, fn func(entity.Click) error) error {
+	builder := r.selectClicks(shortCode, filter,
+		"u.short_code",
+		"COALESCE(abbrev(c.ip_address), '')",
+		"c.ip_hash",
+		"c.user_agent",
+		"c.device",
+		"c.browser_family",
+		"c.browser_version",
+		"c.os",
+		"c.referrer",
+		"c.referrer_host",
+		"c.country",
+		"c.region",
+		"c.is_bot",
+		"c.is_repeat",
+		"c.clicked_at",
+	).OrderBy("c.clicked_at")
+
+	sql, args, err := builder.ToSql()
+	if err != nil {
+		return fmt.Errorf("LinkRepo - StreamClicks - r.Builder.ToSql: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
-		return fmt.Errorf("LinkRepo - CreateClick - r.Pool.Exec: %w", err)
+		return fmt.Errorf("LinkRepo - StreamClicks - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c entity.Click
+		if err := rows.Scan(
+			&c.ShortCode,
+			&c.IP,
+			&c.IPHash,
+			&c.UserAgent,
+			&c.Device,
+			&c.Browser,
+			&c.BrowserVersion,
+			&c.OS,
+			&c.Referrer,
+			&c.ReferrerHost,
+			&c.Country,
+			&c.Region,
+			&c.IsBot,
+			&c.IsRepeat,
+			&c.ClickedAt,
+		); err != nil {
+			return fmt.Errorf("LinkRepo - StreamClicks - rows.Scan: %w", err)
+		}
+
+		if err := fn(c); err != nil {
+			return fmt.Errorf("LinkRepo - StreamClicks - fn: %w", err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("LinkRepo - StreamClicks - rows.Err: %w", err)
 	}
 
 	return nil
 }
 
-func (r *LinkRepo) GetAnalytics(ctx context.Context, shortCode string) (entity.Analytics, error) {
-	totalClicks, err := r.getTotalClicks(ctx, shortCode)
+func (r *LinkRepo) GetIDsByShortCodes(ctx context.Context, shortCodes []string) (map[string]int64, error) {
+	sql, args, err := r.Builder.
+		Select(idColumn, shortCodeColumn).
+		From(urlsTable).
+		Where(squirrel.Eq{shortCodeColumn: shortCodes}).
+		ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetIDsByShortCodes - r.Builder.ToSql: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetIDsByShortCodes - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	IDs := make(map[string]int64, len(shortCodes))
+
+	for rows.Next() {
+		var (
+			ID        int64
+			shortCode string
+		)
+		if err := rows.Scan(&ID, &shortCode); err != nil {
+			return nil, fmt.Errorf("LinkRepo - GetIDsByShortCodes - rows.Scan: %w", err)
+		}
+		IDs[shortCode] = ID
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetIDsByShortCodes - rows.Err: %w", err)
+	}
+
+	return IDs, nil
+}
+
+// CreateClicks writes clicks in one round trip using the COPY protocol.
+func (r *LinkRepo) CreateClicks(ctx context.Context, clicks []entity.Click) (int64, error) {
+	rows := make([][]any, 0, len(clicks))
+	for _, c := range clicks {
+		rows = append(rows, []any{c.URLID, ipValue(c.IP), c.IPHash, c.UserAgent, c.Browser, c.BrowserVersion, c.OS, c.Device,
+			c.Referrer, c.ReferrerHost,
+			c.Country, c.Region, c.IsBot, c.IsRepeat, c.ClickedAt})
+	}
+
+	n, err := r.Pool.CopyFrom(
+		ctx,
+		pgx.Identifier{clicksTable},
+		[]string{urlIdColumn, ipAddrColumn, ipHashColumn, userAgentColumn, browserFamilyColumn, browserVersionColumn, osColumn, deviceColumn,
+			referrerColumn, referrerHostColumn, countryColumn, regionColumn, isBotColumn, isRepeatColumn, clickedAtColumn},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("LinkRepo - CreateClicks - r.Pool.CopyFrom: %w", err)
+	}
+
+	return n, nil
+}
+
+func (r *LinkRepo) GetAnalytics(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) (entity.Analytics, error) {
+	totalClicks, repeatClicks, err := r.getTotalClicks(ctx, shortCode, filter)
 	if err != nil {
 		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.getTotalClicks: %w", err)
 	}
 
-	clicksByBrowser, err := r.GetClicksByBrowser(ctx, shortCode)
+	clicksByBrowser, err := r.GetClicksByBrowser(ctx, shortCode, filter)
 	if err != nil {
 		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.getClicksByBrowser: %w", err)
 	}
 
-	clicksByDevice, err := r.GetClicksByDevice(ctx, shortCode)
+	clicksByDevice, err := r.GetClicksByDevice(ctx, shortCode, filter)
 	if err != nil {
 		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.getClicksByDevice: %w", err)
 	}
 
+	clicksByOS, err := r.GetClicksByOS(ctx, shortCode, filter)
+	if err != nil {
+		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.GetClicksByOS: %w", err)
+	}
+
+	clicksByReferrer, err := r.GetClicksByReferrer(ctx, shortCode, filter)
+	if err != nil {
+		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.GetClicksByReferrer: %w", err)
+	}
+
+	clicksByCountry, err := r.GetClicksByCountry(ctx, shortCode, filter)
+	if err != nil {
+		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.GetClicksByCountry: %w", err)
+	}
+
+	clicksByBot, err := r.GetClicksByBot(ctx, shortCode, filter)
+	if err != nil {
+		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.GetClicksByBot: %w", err)
+	}
+
 	// if we want full analytics - interval == day by default
-	recentClicks, err := r.GetRecentClicks(ctx, shortCode, "day")
+	recentClicks, err := r.GetRecentClicks(ctx, shortCode, "day", filter)
 	if err != nil {
 		return entity.Analytics{}, fmt.Errorf("LinkRepo - GetAnalytics - r.getRecentClicks: %w", err)
 	}
 
 	return entity.Analytics{
-		TotalClicks:     totalClicks,
-		ClicksByBrowser: clicksByBrowser,
-		ClicksByDevice:  clicksByDevice,
-		RecentClicks:    recentClicks,
+		TotalClicks:        totalClicks,
+		DeduplicatedClicks: totalClicks - repeatClicks,
+		ClicksByBrowser:    clicksByBrowser,
+		ClicksByDevice:     clicksByDevice,
+		ClicksByOS:         clicksByOS,
+		ClicksByReferrer:   clicksByReferrer,
+		ClicksByCountry:    clicksByCountry,
+		ClicksByBot:        clicksByBot,
+		RecentClicks:       recentClicks,
 	}, nil
 }
 
-func (r *LinkRepo) getTotalClicks(ctx context.Context, shortCode string) (int64, error) {
-	sql := `
-	SELECT COUNT(*) AS total_clicks
-	FROM clicks c
-	JOIN urls u ON u.id = c.url_id
-	WHERE u.short_code = $1;
-	`
+// selectClicks starts a query over clicks of the short code, narrowed by the filter.
+func (r *LinkRepo) selectClicks(shortCode string, filter entity.AnalyticsFilter, columns ...string) squirrel.SelectBuilder {
+	builder := r.Builder.
+		Select(columns...).
+		From("clicks c").
+		Join("urls u ON u.id = c.url_id").
+		Where(squirrel.Eq{"u.short_code": shortCode})
 
-	var total int64
+	if filter.From != nil {
+		builder = builder.Where(squirrel.GtOrEq{"c.clicked_at": *filter.From})
+	} else {
+		builder = builder.Where(clickedSinceCreation("c.clicked_at", shortCode))
+	}
+	if filter.To != nil {
+		builder = builder.Where(squirrel.Lt{"c.clicked_at": *filter.To})
+	}
+	if !filter.IncludeBots {
+		builder = builder.Where(squirrel.Eq{"c.is_bot": false})
+	}
+
+	return builder
+}
+
+// clickedSinceCreation bounds clicks of the link by its creation time. The scalar subquery
+// is computed before the scan, so reads without "from" still skip partitions older than the link.
+func clickedSinceCreation(column, shortCode string) squirrel.Sqlizer {
+	return squirrel.Expr(
+		column+" >= (SELECT COALESCE(created_at, '-infinity') FROM urls WHERE short_code = ?)",
+		shortCode,
+	)
+}
+
+// getTotalClicks returns all clicks and repeat clicks among them.
+func (r *LinkRepo) getTotalClicks(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) (int64, int64, error) {
+	source, args, err := r.clickSource(ctx, shortCode, filter, day)
+	if err != nil {
+		return 0, 0, fmt.Errorf("LinkRepo - getTotalClicks - r.clickSource: %w", err)
+	}
 
-	row := r.Pool.QueryRow(ctx, sql, shortCode)
-	err := row.Scan(&total)
+	sql, err := squirrel.Dollar.ReplacePlaceholders(
+		"SELECT COALESCE(SUM(clicks), 0)::bigint AS total_clicks, " +
+			"COALESCE(SUM(repeat_clicks), 0)::bigint AS repeat_clicks FROM (" + source + ") s",
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("LinkRepo - getTotalClicks - squirrel.Dollar.ReplacePlaceholders: %w", err)
+	}
+
+	var total, repeats int64
+
+	row := r.Pool.QueryRow(ctx, sql, args...)
+	err = row.Scan(&total, &repeats)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return 0, fmt.Errorf("LinkRepo - getTotalClicks: %w", errs.ErrRecordNotFound)
+			return 0, 0, fmt.Errorf("LinkRepo - getTotalClicks: %w", errs.ErrRecordNotFound)
 		}
-		return 0, fmt.Errorf("LinkRepo - getTotalClicks - row.Scan: %w", err)
+		return 0, 0, fmt.Errorf("LinkRepo - getTotalClicks - row.Scan: %w", err)
 	}
 
-	return total, nil
+	return total, repeats, nil
 }
 
-func (r *LinkRepo) GetClicksByBrowser(ctx context.Context, shortCode string) ([]entity.ClickByBrowser, error) {
-	sql := `
-	SELECT 
-		c.browser_family, 
-		COUNT (*) AS clicks
-	FROM clicks c
-	JOIN urls u ON u.id = c.url_id
-	WHERE u.short_code = $1
-	GROUP by c.browser_family
-	ORDER BY clicks DESC;
-	`
+// groupClicks sums clicks of the source by one of its dimension columns, biggest first.
+// Columns that are not rollup dimensions are read from raw clicks.
+func (r *LinkRepo) groupClicks(ctx context.Context, shortCode string, filter entity.AnalyticsFilter,
+	column string,
+) (pgx.Rows, error) {
+	granularity := day
+	if !slices.Contains(rollupDimensions, column) {
+		granularity = 0
+	}
 
-	rows, err := r.Pool.Query(ctx, sql, shortCode)
+	source, args, err := r.clickSource(ctx, shortCode, filter, granularity)
 	if err != nil {
-		return nil, fmt.Errorf("LinkRepo - getClicksByBrowser - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("r.clickSource: %w", err)
+	}
+
+	sql, err := squirrel.Dollar.ReplacePlaceholders(
+		"SELECT " + column + ", SUM(clicks)::bigint AS clicks FROM (" + source + ") s " +
+			"GROUP BY " + column + " ORDER BY clicks DESC",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("squirrel.Dollar.ReplacePlaceholders: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, fmt.Errorf("r.Pool.Query: %w", err)
+	}
+
+	return rows, nil
+}
+
+func (r *LinkRepo) GetClicksByBrowser(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBrowser, error) {
+	rows, err := r.groupClicks(ctx, shortCode, filter, browserFamilyColumn)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - getClicksByBrowser - r.groupClicks: %w", err)
 	}
 	defer rows.Close()
 
@@ -246,22 +644,12 @@ func (r *LinkRepo) GetClicksByBrowser(ctx context.Context, shortCode string) ([]
 	return clicks, nil
 }
 
-func (r *LinkRepo) GetClicksByDevice(ctx context.Context, shortCode string) ([]entity.ClickByDevice, error) {
-	sql := `
-	SELECT
-		c.device,
-		COUNT (*) AS clicks
-	FROM clicks c
-	JOIN urls u ON u.id = c.url_id
-	WHERE u.short_code = $1
-	GROUP BY c.device
-	ORDER BY clicks DESC;
-	`
-
-	rows, err := r.Pool.Query(ctx, sql, shortCode)
+func (r *LinkRepo) GetClicksByDevice(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByDevice, error) {
+	rows, err := r.groupClicks(ctx, shortCode, filter, deviceColumn)
 	if err != nil {
-		return nil, fmt.Errorf("LinkRepo - getClicksByDevice - r.Pool.Query: %w", err)
+		return nil, fmt.Errorf("LinkRepo - getClicksByDevice - r.groupClicks: %w", err)
 	}
+	defer rows.Close()
 
 	clicks := make([]entity.ClickByDevice, 0)
 
@@ -279,23 +667,167 @@ func (r *LinkRepo) GetClicksByDevice(ctx context.Context, shortCode string) ([]e
 	return clicks, nil
 }
 
-func (r *LinkRepo) GetRecentClicks(ctx context.Context, shortCode, interval string) ([]entity.ClickByDate, error) {
+func (r *LinkRepo) GetClicksByOS(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByOS, error) {
+	rows, err := r.groupClicks(ctx, shortCode, filter, osColumn)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetClicksByOS - r.groupClicks: %w", err)
+	}
+	defer rows.Close()
+
+	clicks := make([]entity.ClickByOS, 0)
+
+	for rows.Next() {
+		var c entity.ClickByOS
+		if err := rows.Scan(
+			&c.OS,
+			&c.Clicks,
+		); err != nil {
+			return nil, fmt.Errorf("LinkRepo - GetClicksByOS - rows.Scan: %w", err)
+		}
+		clicks = append(clicks, c)
+	}
+
+	return clicks, nil
+}
+
+func (r *LinkRepo) GetClicksByReferrer(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByReferrer, error) {
+	rows, err := r.groupClicks(ctx, shortCode, filter, referrerHostColumn)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetClicksByReferrer - r.groupClicks: %w", err)
+	}
+	defer rows.Close()
+
+	clicks := make([]entity.ClickByReferrer, 0)
+
+	for rows.Next() {
+		var c entity.ClickByReferrer
+		if err := rows.Scan(
+			&c.Referrer,
+			&c.Clicks,
+		); err != nil {
+			return nil, fmt.Errorf("LinkRepo - GetClicksByReferrer - rows.Scan: %w", err)
+		}
+		clicks = append(clicks, c)
+	}
+
+	return clicks, nil
+}
+
+func (r *LinkRepo) GetClicksByCountry(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByCountry, error) {
+	rows, err := r.groupClicks(ctx, shortCode, filter, countryColumn)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetClicksByCountry - r.groupClicks: %w", err)
+	}
+	defer rows.Close()
+
+	clicks := make([]entity.ClickByCountry, 0)
+
+	for rows.Next() {
+		var c entity.ClickByCountry
+		if err := rows.Scan(
+			&c.Country,
+			&c.Clicks,
+		); err != nil {
+			return nil, fmt.Errorf("LinkRepo - GetClicksByCountry - rows.Scan: %w", err)
+		}
+		clicks = append(clicks, c)
+	}
+
+	return clicks, nil
+}
+
+// GetClicksByBot counts human and bot clicks, bots are counted whatever the filter says.
+func (r *LinkRepo) GetClicksByBot(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBot, error) {
+	filter.IncludeBots = true
+
+	rows, err := r.groupClicks(ctx, shortCode, filter, isBotColumn)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetClicksByBot - r.groupClicks: %w", err)
+	}
+	defer rows.Close()
+
+	clicks := make([]entity.ClickByBot, 0)
+
+	for rows.Next() {
+		var c entity.ClickByBot
+		if err := rows.Scan(
+			&c.IsBot,
+			&c.Clicks,
+		); err != nil {
+			return nil, fmt.Errorf("LinkRepo - GetClicksByBot - rows.Scan: %w", err)
+		}
+		clicks = append(clicks, c)
+	}
+
+	return clicks, nil
+}
+
+// GetRecentClicks returns a continuous series of the latest recentClicksLimit buckets, oldest first,
+// buckets without clicks have zero count. The series starts at the range start (or the first click)
+// and ends at the range end, never later than now. Rollups are used when their buckets fit the time zone.
+// Buckets are truncated on the wall clock of the filter time zone, Date is the bucket start.
+func (r *LinkRepo) GetRecentClicks(ctx context.Context, shortCode, interval string, filter entity.AnalyticsFilter) ([]entity.ClickByDate, error) {
+	tz := filter.TZ().String()
+
+	// parts are built with "?" and numbered once the whole statement is assembled
+	granularity := rollupGranularity(filter)
+	if interval == "hour" {
+		granularity = min(granularity, time.Hour)
+	}
+
+	source, sourceArgs, err := r.clickSource(ctx, shortCode, filter, granularity)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetRecentClicks - r.clickSource: %w", err)
+	}
+
+	// "to" is exclusive, the last bucket is the one holding its previous instant
+	var last *time.Time
+	if filter.To != nil {
+		t := filter.To.Add(-time.Microsecond)
+		last = &t
+	}
+
 	sql := `
+	WITH counts AS (
+		SELECT
+			date_trunc(?, s.bucket AT TIME ZONE ?::text) AS bucket,
+			SUM(s.clicks)::bigint AS clicks
+		FROM (` + source + `) s
+		GROUP BY 1
+	), bounds AS (
+		SELECT
+			COALESCE(
+				date_trunc(?, ?::timestamptz AT TIME ZONE ?::text),
+				(SELECT MIN(bucket) FROM counts)
+			) AS first_bucket,
+			date_trunc(?, LEAST(?::timestamptz, now()) AT TIME ZONE ?::text) AS last_bucket
+	), buckets AS (
+		SELECT generate_series(b.last_bucket, b.first_bucket, ?::interval) AS bucket
+		FROM bounds b
+		LIMIT ?
+	)
 	SELECT
-		date_trunc($2, c.clicked_at) AS click_date,
-		COUNT (*) AS clicks
-	FROM clicks c
-	JOIN urls u ON u.id = c.url_id
-	WHERE u.short_code = $1
-	GROUP BY click_date
-	ORDER BY click_date
-	LIMIT 90;
+		bk.bucket AT TIME ZONE ?::text AS click_date,
+		COALESCE(cn.clicks, 0) AS clicks
+	FROM buckets bk
+	LEFT JOIN counts cn ON cn.bucket = bk.bucket
+	ORDER BY bk.bucket;
 	`
 
-	rows, err := r.Pool.Query(ctx, sql, shortCode, interval)
+	args := []any{interval, tz}
+	args = append(args, sourceArgs...)
+	args = append(args, interval, filter.From, tz, interval, last, tz, "-1 "+interval, recentClicksLimit, tz)
+
+	sql, err = squirrel.Dollar.ReplacePlaceholders(sql)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - GetRecentClicks - squirrel.Dollar.ReplacePlaceholders: %w", err)
+	}
+
+	rows, err := r.Pool.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("LinkRepo - GetRecentClicks - r.Pool.Query: %w", err)
 	}
+	defer rows.Close()
 
 	clicks := make([]entity.ClickByDate, 0)
 
@@ -334,3 +866,50 @@ func (r *LinkRepo) ExistsByShortCode(ctx context.Context, shortCode string) erro
 
 	return nil
 }
+
+func (r *LinkRepo) DeleteExpired(ctx context.Context, before time.Time, limit int) ([]entity.Link, error) {
+	sql := `
+	DELETE FROM urls
+	WHERE id IN (
+		SELECT id
+		FROM urls
+		WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	)
+	RETURNING id, short_code;
+	`
+
+	rows, err := r.Pool.Query(ctx, sql, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - DeleteExpired - r.Pool.Query: %w", err)
+	}
+	defer rows.Close()
+
+	links := make([]entity.Link, 0)
+
+	for rows.Next() {
+		var link entity.Link
+		if err := rows.Scan(&link.ID, &link.ShortCode); err != nil {
+			return nil, fmt.Errorf("LinkRepo - DeleteExpired - rows.Scan: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("LinkRepo - DeleteExpired - rows.Err: %w", err)
+	}
+
+	return links, nil
+}
+
+// isUniqueViolation reports whether err is a Postgres violation of one of the unique constraints.
+func isUniqueViolation(err error, constraints ...string) bool {
+	var pgErr *pgconn.PgError
+
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolationCode {
+		return false
+	}
+
+	return slices.Contains(constraints, pgErr.ConstraintName)
+}
@@ -0,0 +1,118 @@
+package persistent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/pkg/postgres"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+)
+
+// newTestRepo connects to the migrated database in PG_TEST_URL, tests are skipped without it.
+func newTestRepo(t *testing.T) *LinkRepo {
+	t.Helper()
+
+	url := os.Getenv("PG_TEST_URL")
+	if url == "" {
+		t.Skip("PG_TEST_URL is not set")
+	}
+
+	pg, err := postgres.New(url, postgres.MaxPoolSize(4), postgres.ConnAttempts(1))
+	if err != nil {
+		t.Fatalf("postgres.New: %v", err)
+	}
+	t.Cleanup(pg.Close)
+
+	return New(pg)
+}
+
+func testAlias(name string) string {
+	return fmt.Sprintf("test-%s-%d", name, time.Now().UnixNano())
+}
+
+func TestCreateWithShortCodeAliasRace(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	alias := testAlias("race")
+	t.Cleanup(func() { _, _ = r.DeleteByShortCode(ctx, alias) })
+
+	const callers = 2
+
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		results = make([]error, callers)
+	)
+
+	for i := range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			<-start
+			results[i] = r.CreateWithShortCode(ctx, entity.Link{
+				URL:       fmt.Sprintf("https://example.com/%d", i),
+				ShortCode: alias,
+				IsCustom:  true,
+			})
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	var created, taken int
+	for _, err := range results {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, errs.ErrAliasAlreadyTaken):
+			taken++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if created != 1 || taken != 1 {
+		t.Fatalf("created %d, taken %d, want 1 and 1", created, taken)
+	}
+}
+
+func TestCreateWithShortCodeTakenIDIsNotAliasConflict(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	alias := testAlias("id")
+	t.Cleanup(func() { _, _ = r.DeleteByShortCode(ctx, alias) })
+
+	err := r.CreateWithShortCode(ctx, entity.Link{URL: "https://example.com", ShortCode: alias, IsCustom: true})
+	if err != nil {
+		t.Fatalf("CreateWithShortCode: %v", err)
+	}
+
+	link, err := r.GetByShortCode(ctx, alias)
+	if err != nil {
+		t.Fatalf("GetByShortCode: %v", err)
+	}
+
+	// a free short code with the primary key of an existing link violates urls_pkey only
+	other := testAlias("other")
+	t.Cleanup(func() { _, _ = r.DeleteByShortCode(ctx, other) })
+
+	err = r.CreateWithShortCode(ctx, entity.Link{ID: link.ID, URL: "https://example.com", ShortCode: other})
+	if err == nil {
+		t.Fatal("expected a primary key violation")
+	}
+
+	if errors.Is(err, errs.ErrAliasAlreadyTaken) {
+		t.Fatalf("primary key violation reported as taken alias: %v", err)
+	}
+}
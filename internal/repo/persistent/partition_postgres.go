@@ -0,0 +1,107 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Partitions of clicks hold one UTC month each and are named clicks_pYYYY_MM.
+const clicksPartitionLayout = "clicks_p2006_01"
+
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func (r *LinkRepo) CreateClickPartitions(ctx context.Context, from, to time.Time) ([]string, error) {
+	created := make([]string, 0)
+
+	for month := monthStart(from); !month.After(to); month = month.AddDate(0, 1, 0) {
+		name := month.Format(clicksPartitionLayout)
+
+		var exists bool
+
+		err := r.Pool.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists)
+		if err != nil {
+			return created, fmt.Errorf("LinkRepo - CreateClickPartitions - r.Pool.QueryRow: %w", err)
+		}
+		if exists {
+			continue
+		}
+
+		// DDL takes no bind parameters, bounds are formatted by the server
+		sql := fmt.Sprintf(
+			`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+			pgx.Identifier{name}.Sanitize(),
+			clicksTable,
+			month.Format(time.RFC3339),
+			month.AddDate(0, 1, 0).Format(time.RFC3339),
+		)
+
+		_, err = r.Pool.Exec(ctx, sql)
+		if err != nil {
+			return created, fmt.Errorf("LinkRepo - CreateClickPartitions - r.Pool.Exec: %w", err)
+		}
+
+		created = append(created, name)
+	}
+
+	return created, nil
+}
+
+func (r *LinkRepo) DropClickPartitions(ctx context.Context, before time.Time) ([]string, error) {
+	rows, err := r.Pool.Query(ctx, `
+	SELECT c.relname
+	FROM pg_inherits i
+	JOIN pg_class c ON c.oid = i.inhrelid
+	WHERE i.inhparent = $1::regclass;
+	`, clicksTable)
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - DropClickPartitions - r.Pool.Query: %w", err)
+	}
+
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("LinkRepo - DropClickPartitions - pgx.CollectRows: %w", err)
+	}
+
+	dropped := make([]string, 0)
+
+	for _, name := range names {
+		month, err := time.Parse(clicksPartitionLayout, name)
+		if err != nil {
+			// not created by us, leave it alone
+			continue
+		}
+
+		if month.AddDate(0, 1, 0).After(before) {
+			continue
+		}
+
+		// clicks written late may wait for the next rollup, they must not be lost
+		var pending bool
+
+		err = r.Pool.QueryRow(ctx,
+			"SELECT EXISTS (SELECT 1 FROM "+pgx.Identifier{name}.Sanitize()+" WHERE NOT "+rolledUpColumn+")",
+		).Scan(&pending)
+		if err != nil {
+			return dropped, fmt.Errorf("LinkRepo - DropClickPartitions - r.Pool.QueryRow: %w", err)
+		}
+		if pending {
+			continue
+		}
+
+		_, err = r.Pool.Exec(ctx, "DROP TABLE IF EXISTS "+pgx.Identifier{name}.Sanitize())
+		if err != nil {
+			return dropped, fmt.Errorf("LinkRepo - DropClickPartitions - r.Pool.Exec: %w", err)
+		}
+
+		dropped = append(dropped, name)
+	}
+
+	return dropped, nil
+}
@@ -0,0 +1,98 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"time"
+)
+
+const (
+	// Table
+	clickAnonymizeWatermarkTable = "click_anonymize_watermark"
+
+	// Column
+	anonymizedToColumn = "anonymized_to"
+)
+
+// ipValue converts an address or a network ("203.0.113.0/24") to an inet value, nil for anything else.
+func ipValue(ip string) any {
+	if prefix, err := netip.ParsePrefix(ip); err == nil {
+		return prefix
+	}
+
+	if addr, err := netip.ParseAddr(ip); err == nil {
+		return netip.PrefixFrom(addr, addr.BitLen())
+	}
+
+	return nil
+}
+
+func (r *LinkRepo) GetAnonymizeWatermark(ctx context.Context) (time.Time, error) {
+	sql, args, err := r.Builder.
+		Select(anonymizedToColumn).
+		From(clickAnonymizeWatermarkTable).
+		ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - GetAnonymizeWatermark - r.Builder.ToSql: %w", err)
+	}
+
+	var watermark time.Time
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&watermark)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - GetAnonymizeWatermark - r.Pool.QueryRow: %w", err)
+	}
+
+	return watermark, nil
+}
+
+// AnonymizeClicks truncates IPs of clicks from the watermark up to upTo to networks of ipv4Bits/ipv6Bits
+// and clears their IP hashes, user agents and full referrers, then moves the watermark, all in one transaction.
+// Returns the new watermark.
+func (r *LinkRepo) AnonymizeClicks(ctx context.Context, upTo time.Time, ipv4Bits, ipv6Bits int) (time.Time, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - AnonymizeClicks - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var from time.Time
+
+	err = tx.QueryRow(ctx, `SELECT anonymized_to FROM click_anonymize_watermark FOR UPDATE`).Scan(&from)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - AnonymizeClicks - tx.QueryRow: %w", err)
+	}
+
+	if !from.Before(upTo) {
+		return from, nil
+	}
+
+	// clicks stored without identifiers (truncate mode, do-not-track) are left as they are
+	_, err = tx.Exec(ctx, `
+	UPDATE clicks SET
+		ip_address = network(set_masklen(ip_address,
+			LEAST(masklen(ip_address), CASE family(ip_address) WHEN 4 THEN $3::int ELSE $4::int END)))::inet,
+		ip_hash = '',
+		user_agent = '',
+		referrer = ''
+	WHERE clicked_at >= $1 AND clicked_at < $2
+		AND (user_agent <> '' OR ip_hash <> '' OR referrer <> ''
+			OR masklen(ip_address) > CASE family(ip_address) WHEN 4 THEN $3::int ELSE $4::int END)
+	`, from, upTo, ipv4Bits, ipv6Bits)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - AnonymizeClicks - tx.Exec: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `UPDATE click_anonymize_watermark SET anonymized_to = $1`, upTo)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - AnonymizeClicks - tx.Exec watermark: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - AnonymizeClicks - tx.Commit: %w", err)
+	}
+
+	return upTo, nil
+}
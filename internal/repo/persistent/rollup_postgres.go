@@ -0,0 +1,348 @@
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/squirrel"
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+)
+
+const (
+	// Table
+	clickRollupsHourlyTable   = "click_rollups_hourly"
+	clickRollupsDailyTable    = "click_rollups_daily"
+	clickRollupWatermarkTable = "click_rollup_watermark"
+
+	// Column
+	bucketColumn       = "bucket"
+	clicksColumn       = "clicks"
+	repeatClicksColumn = "repeat_clicks"
+	rolledUpToColumn   = "rolled_up_to"
+	rolledUpColumn     = "rolled_up"
+
+	day = 24 * time.Hour
+)
+
+// rollupDimensions are the click attributes rollups are grouped by, analytics can group by any of them.
+// Referrer hosts are nearly as many as clicks, so they are left out and read from raw clicks.
+var rollupDimensions = []string{browserFamilyColumn, deviceColumn, osColumn, countryColumn, isBotColumn}
+
+// rawDimensions are the click attributes analytics can group by when it reads raw clicks only.
+var rawDimensions = append(slices.Clone(rollupDimensions), referrerHostColumn)
+
+// timeRange is [from, to), nil bounds are open.
+type timeRange struct {
+	from *time.Time
+	to   *time.Time
+}
+
+func (tr timeRange) empty() bool {
+	return tr.from != nil && tr.to != nil && !tr.from.Before(*tr.to)
+}
+
+func (tr timeRange) where(column string) squirrel.And {
+	cond := squirrel.And{}
+	if tr.from != nil {
+		cond = append(cond, squirrel.GtOrEq{column: *tr.from})
+	}
+	if tr.to != nil {
+		cond = append(cond, squirrel.Lt{column: *tr.to})
+	}
+
+	return cond
+}
+
+// clickRanges tells which part of the requested range is read from which table.
+type clickRanges struct {
+	daily  []timeRange
+	hourly []timeRange
+	raw    []timeRange
+}
+
+// splitClickRange splits [from, to) so that whole UTC days before the watermark come from daily rollups,
+// whole hours from hourly rollups and the rest from raw clicks. Clicks written after their hour was rolled up
+// are read raw in the rollup ranges too, until the next rollup picks them up.
+// granularity limits the rollups that may be used: day, hour or 0 for raw clicks only.
+func splitClickRange(from, to *time.Time, watermark time.Time, granularity time.Duration) clickRanges {
+	all := clickRanges{raw: []timeRange{{from: from, to: to}}}
+
+	if granularity < time.Hour {
+		return all
+	}
+
+	// rolled-up part of the range aligned to hours: [hourLo, hourHi), nil hourLo is open
+	rolled := watermark
+	if to != nil && to.Before(rolled) {
+		rolled = *to
+	}
+
+	hourHi := rolled.UTC().Truncate(time.Hour)
+
+	var hourLo *time.Time
+	if from != nil {
+		t := ceilTime(*from, time.Hour)
+		hourLo = &t
+	}
+
+	if hourLo != nil && !hourLo.Before(hourHi) {
+		return all
+	}
+
+	ranges := clickRanges{
+		raw: []timeRange{{from: &hourHi, to: to}},
+	}
+	// an open hourLo means there is nothing before the rollups
+	if hourLo != nil {
+		ranges.raw = append(ranges.raw, timeRange{from: from, to: hourLo})
+	}
+
+	dayHi := hourHi.Truncate(day)
+
+	var dayLo *time.Time
+	if hourLo != nil {
+		t := ceilTime(*hourLo, day)
+		dayLo = &t
+	}
+
+	if granularity < day || (dayLo != nil && !dayLo.Before(dayHi)) {
+		ranges.hourly = []timeRange{{from: hourLo, to: &hourHi}}
+
+		return ranges
+	}
+
+	ranges.daily = []timeRange{{from: dayLo, to: &dayHi}}
+	ranges.hourly = []timeRange{{from: &dayHi, to: &hourHi}}
+	if dayLo != nil {
+		ranges.hourly = append(ranges.hourly, timeRange{from: hourLo, to: dayLo})
+	}
+
+	return ranges
+}
+
+func ceilTime(t time.Time, d time.Duration) time.Time {
+	t = t.UTC()
+
+	c := t.Truncate(d)
+	if c.Before(t) {
+		c = c.Add(d)
+	}
+
+	return c
+}
+
+// rollupGranularity returns the coarsest rollup whose UTC buckets line up with buckets in the filter zone.
+func rollupGranularity(filter entity.AnalyticsFilter) time.Duration {
+	loc := filter.TZ()
+	if loc.String() == "UTC" {
+		return day
+	}
+
+	points := []time.Time{time.Now()}
+	if filter.From != nil {
+		points = append(points, *filter.From)
+	}
+	if filter.To != nil {
+		points = append(points, *filter.To)
+	}
+
+	for _, p := range points {
+		_, offset := p.In(loc).Zone()
+		if offset%int(time.Hour/time.Second) != 0 {
+			return 0
+		}
+	}
+
+	return time.Hour
+}
+
+// clickSource returns a "?" placeholder subquery with bucket, rollup dimension, clicks and repeat_clicks columns
+// that covers clicks of the short code in the filter range, reading rollups where possible.
+// Without rollups (granularity 0) the subquery has all raw dimensions.
+func (r *LinkRepo) clickSource(ctx context.Context, shortCode string, filter entity.AnalyticsFilter,
+	granularity time.Duration,
+) (string, []any, error) {
+	watermark, err := r.GetRollupWatermark(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("LinkRepo - clickSource - r.GetRollupWatermark: %w", err)
+	}
+
+	ranges := splitClickRange(filter.From, filter.To, watermark, granularity)
+
+	urlID := squirrel.Expr("url_id = (SELECT id FROM urls WHERE short_code = ?)", shortCode)
+
+	var (
+		parts []string
+		args  []any
+	)
+
+	add := func(builder squirrel.SelectBuilder, tr timeRange, column string) error {
+		if tr.empty() {
+			return nil
+		}
+
+		builder = builder.
+			Where(urlID).
+			Where(tr.where(column))
+		if !filter.IncludeBots {
+			builder = builder.Where(squirrel.Eq{isBotColumn: false})
+		}
+		// rollup buckets start before the creation time, only raw clicks are bounded by it
+		if tr.from == nil && column == clickedAtColumn {
+			builder = builder.Where(clickedSinceCreation(column, shortCode))
+		}
+
+		sql, partArgs, err := builder.
+			PlaceholderFormat(squirrel.Question).
+			ToSql()
+		if err != nil {
+			return err
+		}
+
+		parts = append(parts, sql)
+		args = append(args, partArgs...)
+
+		return nil
+	}
+
+	rollup := func(table string) squirrel.SelectBuilder {
+		columns := append([]string{bucketColumn}, rollupDimensions...)
+
+		return squirrel.Select(append(columns, clicksColumn, repeatClicksColumn)...).From(table)
+	}
+
+	dims := rollupDimensions
+	if granularity < time.Hour {
+		dims = rawDimensions
+	}
+
+	rawColumns := append([]string{clickedAtColumn + " AS " + bucketColumn}, dims...)
+	raw := squirrel.
+		Select(append(rawColumns, "1 AS "+clicksColumn, isRepeatColumn+"::int AS "+repeatClicksColumn)...).
+		From(clicksTable)
+	late := raw.Where("NOT " + rolledUpColumn)
+
+	for _, tr := range ranges.daily {
+		if err := add(rollup(clickRollupsDailyTable), tr, bucketColumn); err != nil {
+			return "", nil, fmt.Errorf("LinkRepo - clickSource - daily: %w", err)
+		}
+		if err := add(late, tr, clickedAtColumn); err != nil {
+			return "", nil, fmt.Errorf("LinkRepo - clickSource - late: %w", err)
+		}
+	}
+
+	for _, tr := range ranges.hourly {
+		if err := add(rollup(clickRollupsHourlyTable), tr, bucketColumn); err != nil {
+			return "", nil, fmt.Errorf("LinkRepo - clickSource - hourly: %w", err)
+		}
+		if err := add(late, tr, clickedAtColumn); err != nil {
+			return "", nil, fmt.Errorf("LinkRepo - clickSource - late: %w", err)
+		}
+	}
+
+	for _, tr := range ranges.raw {
+		if err := add(raw, tr, clickedAtColumn); err != nil {
+			return "", nil, fmt.Errorf("LinkRepo - clickSource - raw: %w", err)
+		}
+	}
+
+	// the whole range is empty, keep a valid query that finds nothing
+	if len(parts) == 0 {
+		if err := add(raw, timeRange{from: filter.From, to: filter.To}, clickedAtColumn); err != nil {
+			return "", nil, fmt.Errorf("LinkRepo - clickSource - raw: %w", err)
+		}
+	}
+
+	return strings.Join(parts, " UNION ALL "), args, nil
+}
+
+func (r *LinkRepo) GetRollupWatermark(ctx context.Context) (time.Time, error) {
+	sql, args, err := r.Builder.
+		Select(rolledUpToColumn).
+		From(clickRollupWatermarkTable).
+		ToSql()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - GetRollupWatermark - r.Builder.ToSql: %w", err)
+	}
+
+	var watermark time.Time
+
+	err = r.Pool.QueryRow(ctx, sql, args...).Scan(&watermark)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - GetRollupWatermark - r.Pool.QueryRow: %w", err)
+	}
+
+	return watermark, nil
+}
+
+// RollupClicks adds raw clicks before upTo (truncated to the hour) that are not rolled up yet to hourly
+// and daily rollups, marks them rolled up and moves the watermark, all in one transaction.
+// Progress is tracked per click, not by clicked_at, so clicks written late are counted by the next run.
+// Returns the new watermark.
+func (r *LinkRepo) RollupClicks(ctx context.Context, upTo time.Time) (time.Time, error) {
+	tx, err := r.Pool.Begin(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - RollupClicks - r.Pool.Begin: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var from time.Time
+
+	// the row lock keeps concurrent instances from rolling up at the same time
+	err = tx.QueryRow(ctx, `SELECT rolled_up_to FROM click_rollup_watermark FOR UPDATE`).Scan(&from)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - RollupClicks - tx.QueryRow: %w", err)
+	}
+
+	to := upTo.UTC().Truncate(time.Hour)
+	if to.Before(from) {
+		to = from
+	}
+
+	dims := strings.Join(rollupDimensions, ", ")
+	rollup := func(table, unit string) string {
+		return `
+		INSERT INTO ` + table + ` (url_id, bucket, ` + dims + `, clicks, repeat_clicks)
+		SELECT
+			url_id,
+			date_trunc('` + unit + `', clicked_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS rollup_bucket,
+			` + dims + `,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE is_repeat)
+		FROM batch
+		WHERE url_id IS NOT NULL
+		GROUP BY url_id, rollup_bucket, ` + dims + `
+		ON CONFLICT (url_id, bucket, ` + dims + `) DO UPDATE SET
+			clicks = ` + table + `.clicks + EXCLUDED.clicks,
+			repeat_clicks = ` + table + `.repeat_clicks + EXCLUDED.repeat_clicks`
+	}
+
+	// daily rollups get clicks of days in progress too, analytics reads them for whole days before the watermark only
+	_, err = tx.Exec(ctx, `
+	WITH batch AS (
+		UPDATE clicks SET rolled_up = true
+		WHERE NOT rolled_up AND clicked_at < $1
+		RETURNING url_id, clicked_at, `+dims+`, is_repeat
+	), hourly AS (`+rollup(clickRollupsHourlyTable, "hour")+`
+	)`+rollup(clickRollupsDailyTable, "day"), to)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - RollupClicks - tx.Exec rollups: %w", err)
+	}
+
+	if from.Before(to) {
+		_, err = tx.Exec(ctx, `UPDATE click_rollup_watermark SET rolled_up_to = $1`, to)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("LinkRepo - RollupClicks - tx.Exec watermark: %w", err)
+		}
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkRepo - RollupClicks - tx.Commit: %w", err)
+	}
+
+	return to, nil
+}
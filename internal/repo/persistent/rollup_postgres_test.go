@@ -0,0 +1,81 @@
+package persistent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+)
+
+func TestRollupClicksCountsLateClicks(t *testing.T) {
+	r := newTestRepo(t)
+	ctx := context.Background()
+
+	alias := testAlias("late")
+	t.Cleanup(func() { _, _ = r.DeleteByShortCode(ctx, alias) })
+
+	now := time.Now()
+
+	err := r.CreateWithShortCode(ctx, entity.Link{
+		URL:       "https://example.com",
+		ShortCode: alias,
+		IsCustom:  true,
+		CreatedAt: now.Add(-3 * time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("CreateWithShortCode: %v", err)
+	}
+
+	link, err := r.GetByShortCode(ctx, alias)
+	if err != nil {
+		t.Fatalf("GetByShortCode: %v", err)
+	}
+
+	// the hour of the click below is rolled up before the click is written
+	_, err = r.RollupClicks(ctx, now)
+	if err != nil {
+		t.Fatalf("RollupClicks: %v", err)
+	}
+
+	_, err = r.CreateClicks(ctx, []entity.Click{{
+		URLID:        link.ID,
+		UserAgent:    "test",
+		Device:       "Desktop",
+		Browser:      "Chrome",
+		ReferrerHost: "news.example.com",
+		ClickedAt:    now.Add(-2 * time.Hour),
+	}})
+	if err != nil {
+		t.Fatalf("CreateClicks: %v", err)
+	}
+
+	check := func(stage string) {
+		t.Helper()
+
+		analytics, err := r.GetAnalytics(ctx, alias, entity.AnalyticsFilter{})
+		if err != nil {
+			t.Fatalf("%s: GetAnalytics: %v", stage, err)
+		}
+
+		if analytics.TotalClicks != 1 {
+			t.Errorf("%s: %d clicks, want 1", stage, analytics.TotalClicks)
+		}
+
+		if len(analytics.ClicksByReferrer) != 1 || analytics.ClicksByReferrer[0].Referrer != "news.example.com" {
+			t.Errorf("%s: referrers %+v, want news.example.com", stage, analytics.ClicksByReferrer)
+		}
+	}
+
+	check("before rollup")
+
+	// the second run finds nothing new, the click must not be counted twice
+	for range 2 {
+		_, err = r.RollupClicks(ctx, now)
+		if err != nil {
+			t.Fatalf("RollupClicks: %v", err)
+		}
+	}
+
+	check("after rollup")
+}
@@ -2,19 +2,40 @@ package usecase
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/andreyxaxa/URL-Shortener/internal/entity"
 )
 
 type (
 	Link interface {
-		CreateShortURL(ctx context.Context, originalURL string, customAlias string) (string, error)
+		CreateShortURL(ctx context.Context, originalURL string, customAlias string, expiresAt *time.Time) (string, error)
+		CreateShortURLs(ctx context.Context, links []entity.Link) []entity.LinkResult
 		GetOriginalURLByShortCode(ctx context.Context, shortCode string) (string, error)
-		TrackClick(ctx context.Context, shortCode, IP, userAgent string) error
+		GetLink(ctx context.Context, shortCode string) (entity.Link, error)
+		ListLinks(ctx context.Context, limit, offset int) ([]entity.Link, int64, error)
+		UpdateLink(ctx context.Context, shortCode string, update entity.LinkUpdate) (entity.Link, error)
+		DeleteLink(ctx context.Context, shortCode string) error
+		ExportLinks(ctx context.Context, fn func(entity.Link) error) error
+		ExportClicks(ctx context.Context, shortCode string, filter entity.AnalyticsFilter, fn func(entity.Click) error) error
+		// TrackClick queues a click, only ShortCode, IP, UserAgent, Referrer and DoNotTrack are taken from the request
+		TrackClick(ctx context.Context, click entity.Click) error
 		ExistsByShortCode(ctx context.Context, shortCode string) error
-		GetAnalytics(ctx context.Context, shortCode string) (entity.Analytics, error)
-		GetRecentClicks(ctx context.Context, shortCode, interval string) ([]entity.ClickByDate, error)
-		GetClicksByBrowser(ctx context.Context, shortCode string) ([]entity.ClickByBrowser, error)
-		GetClicksByDevice(ctx context.Context, shortCode string) ([]entity.ClickByDevice, error)
+		GetAnalytics(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) (entity.Analytics, error)
+		GetRecentClicks(ctx context.Context, shortCode, interval string, filter entity.AnalyticsFilter) ([]entity.ClickByDate, error)
+		GetClicksByBrowser(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBrowser, error)
+		GetClicksByDevice(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByDevice, error)
+		GetClicksByOS(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByOS, error)
+		GetClicksByReferrer(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByReferrer, error)
+		GetClicksByCountry(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByCountry, error)
+		GetClicksByBot(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBot, error)
+		// SubscribeClicks streams clicks of the link tracked from now on, until ctx is done
+		SubscribeClicks(ctx context.Context, shortCode string) (<-chan entity.ClickEvent, error)
+		GetClickQueueStats() entity.ClickQueueStats
+	}
+
+	Importer interface {
+		Import(ctx context.Context, r io.Reader, format string, dryRun bool) (entity.ImportReport, error)
 	}
 )
@@ -0,0 +1,293 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/internal/repo"
+	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/andreyxaxa/URL-Shortener/pkg/validate"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+
+	// _maxReportErrors caps the number of row errors kept in the report.
+	_maxReportErrors = 1000
+	_maxLineSize     = 1 << 20
+)
+
+// record is one parsed row of the import file.
+type record struct {
+	line int
+	// malformed is set when the row itself couldn't be decoded
+	malformed string
+
+	ShortCode string `json:"short_code"`
+	URL       string `json:"url"`
+	CreatedAt string `json:"created_at"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+// Importer brings code -> URL mappings from other shorteners, keeping the codes as custom aliases.
+type Importer struct {
+	repo repo.LinkRepo
+
+	logger logger.Interface
+}
+
+func New(r repo.LinkRepo, l logger.Interface) *Importer {
+	return &Importer{
+		repo:   r,
+		logger: l,
+	}
+}
+
+// Import reads links in CSV (with header: short_code,url[,created_at][,expires_at]) or NDJSON format.
+// Invalid rows and taken codes are reported and skipped. In dry-run mode nothing is written.
+func (im *Importer) Import(ctx context.Context, r io.Reader, format string, dryRun bool) (entity.ImportReport, error) {
+	report := entity.ImportReport{
+		DryRun: dryRun,
+		Errors: make([]entity.ImportError, 0),
+	}
+
+	// codes seen in this file, a repeated code is a conflict even in dry-run mode
+	seen := make(map[string]struct{})
+
+	handle := func(rec record) error {
+		report.Total++
+
+		link, err := parseRecord(rec)
+		if err != nil {
+			report.Invalid++
+			addError(&report, rec, err.Error())
+
+			return nil
+		}
+
+		if _, ok := seen[link.ShortCode]; ok {
+			report.Conflicts++
+			addError(&report, rec, "duplicate short code in file")
+
+			return nil
+		}
+		seen[link.ShortCode] = struct{}{}
+
+		err = im.importLink(ctx, link, dryRun)
+		switch {
+		case err == nil:
+			report.Imported++
+		case errors.Is(err, errs.ErrAliasAlreadyTaken):
+			report.Conflicts++
+			addError(&report, rec, "short code already taken")
+		default:
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			im.logger.Error(err, "importer - Import")
+
+			report.Failed++
+			addError(&report, rec, "storage problems")
+		}
+
+		return nil
+	}
+
+	var err error
+
+	switch format {
+	case FormatCSV:
+		err = readCSV(r, handle)
+	case FormatNDJSON:
+		err = readNDJSON(r, handle)
+	default:
+		err = fmt.Errorf("unknown format %q: %w", format, errs.ErrInvalidFormat)
+	}
+	if err != nil {
+		return report, fmt.Errorf("Importer - Import: %w", err)
+	}
+
+	return report, nil
+}
+
+func (im *Importer) importLink(ctx context.Context, link entity.Link, dryRun bool) error {
+	if dryRun {
+		err := im.repo.ExistsByShortCode(ctx, link.ShortCode)
+		if err == nil {
+			return errs.ErrAliasAlreadyTaken
+		}
+		if !errors.Is(err, errs.ErrRecordNotFound) {
+			return fmt.Errorf("Importer - importLink - im.repo.ExistsByShortCode: %w", err)
+		}
+
+		return nil
+	}
+
+	err := im.repo.CreateWithShortCode(ctx, link)
+	if err != nil {
+		return fmt.Errorf("Importer - importLink - im.repo.CreateWithShortCode: %w", err)
+	}
+
+	return nil
+}
+
+func addError(report *entity.ImportReport, rec record, msg string) {
+	if len(report.Errors) >= _maxReportErrors {
+		return
+	}
+
+	report.Errors = append(report.Errors, entity.ImportError{
+		Line:      rec.line,
+		ShortCode: rec.ShortCode,
+		Error:     msg,
+	})
+}
+
+// parseRecord validates the row the same way the API validates custom aliases.
+func parseRecord(rec record) (entity.Link, error) {
+	if rec.malformed != "" {
+		return entity.Link{}, errors.New(rec.malformed)
+	}
+
+	if !validate.IsValidURL(rec.URL) {
+		return entity.Link{}, errors.New("invalid url")
+	}
+
+	if !validate.IsValidAlias(rec.ShortCode) {
+		return entity.Link{}, errors.New("invalid short code: use only letters, numbers, dash, underscope")
+	}
+
+	if !validate.IsValidAliasLength(rec.ShortCode) {
+		return entity.Link{}, errors.New("invalid short code length")
+	}
+
+	link := entity.Link{
+		URL:       rec.URL,
+		ShortCode: rec.ShortCode,
+		IsCustom:  true,
+	}
+
+	if rec.CreatedAt != "" {
+		createdAt, err := time.Parse(time.RFC3339, rec.CreatedAt)
+		if err != nil {
+			return entity.Link{}, errors.New("invalid created_at: must be RFC 3339")
+		}
+		link.CreatedAt = createdAt
+	}
+
+	if rec.ExpiresAt != "" {
+		expiresAt, err := time.Parse(time.RFC3339, rec.ExpiresAt)
+		if err != nil {
+			return entity.Link{}, errors.New("invalid expires_at: must be RFC 3339")
+		}
+		link.ExpiresAt = &expiresAt
+	}
+
+	return link, nil
+}
+
+func readCSV(r io.Reader, handle func(record) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("readCSV - reader.Read: %w: missing header", errs.ErrInvalidFormat)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+
+	for _, name := range []string{"short_code", "url"} {
+		if _, ok := columns[name]; !ok {
+			return fmt.Errorf("readCSV: %w: header must contain %s", errs.ErrInvalidFormat, name)
+		}
+	}
+
+	field := func(row []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(row) {
+			return ""
+		}
+
+		return row[i]
+	}
+
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				err = handle(record{line: parseErr.Line, malformed: "invalid csv row"})
+				if err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			return fmt.Errorf("readCSV - reader.Read: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		err = handle(record{
+			line:      line,
+			ShortCode: field(row, "short_code"),
+			URL:       field(row, "url"),
+			CreatedAt: field(row, "created_at"),
+			ExpiresAt: field(row, "expires_at"),
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func readNDJSON(r io.Reader, handle func(record) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), _maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			rec = record{malformed: "invalid json"}
+		}
+		rec.line = line
+
+		err := handle(rec)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("readNDJSON - scanner.Err: %w", err)
+	}
+
+	return nil
+}
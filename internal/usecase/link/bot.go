@@ -0,0 +1,59 @@
+package link
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+
+	"github.com/medama-io/go-useragent"
+)
+
+//go:embed bots.txt
+var _botRulesFile string
+
+// _botRules match user agents of bots the user agent parser misses, _botExceptions human user agents
+// the rules would catch. Both are maintained in bots.txt.
+var _botRules, _botExceptions = parseBotRules(_botRulesFile)
+
+// parseBotRules compiles rules and exceptions of the rule file, each into one case-insensitive
+// regular expression. A broken rule file panics at startup.
+func parseBotRules(file string) (rules, exceptions *regexp.Regexp) {
+	var r, e []string
+
+	for _, line := range strings.Split(file, "\n") {
+		line = strings.TrimSpace(line)
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "!"):
+			e = append(e, strings.TrimPrefix(line, "!"))
+		default:
+			r = append(r, line)
+		}
+	}
+
+	return compileAny(r), compileAny(e)
+}
+
+// compileAny returns a regular expression matching any of patterns, nil for no patterns.
+func compileAny(patterns []string) *regexp.Regexp {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	return regexp.MustCompile("(?i)(?:" + strings.Join(patterns, ")|(?:") + ")")
+}
+
+// isBot tells whether the click was made by a bot rather than a person. Clicks without
+// a user agent are bots as well, browsers always send one.
+func isBot(agent useragent.UserAgent, userAgent string) bool {
+	if strings.TrimSpace(userAgent) == "" || agent.IsBot() {
+		return true
+	}
+
+	if _botRules == nil || !_botRules.MatchString(userAgent) {
+		return false
+	}
+
+	return _botExceptions == nil || !_botExceptions.MatchString(userAgent)
+}
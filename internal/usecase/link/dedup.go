@@ -0,0 +1,77 @@
+package link
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+)
+
+// DedupMode tells what happens to a click repeated by the same visitor within the dedup window.
+type DedupMode string
+
+const (
+	// DedupModeFlag stores repeat clicks marked as repeats, analytics report raw and deduplicated totals.
+	DedupModeFlag DedupMode = "flag"
+	// DedupModeDrop doesn't store repeat clicks at all.
+	DedupModeDrop DedupMode = "drop"
+)
+
+func ParseDedupMode(mode string) (DedupMode, error) {
+	switch DedupMode(mode) {
+	case DedupModeFlag, DedupModeDrop:
+		return DedupMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown click dedup mode %q: must be %s or %s", mode, DedupModeFlag, DedupModeDrop)
+	}
+}
+
+// dedupKey is set by the first click of the visitor on the link and lives for the dedup window.
+// It is keyed by the link ID, so a re-created short code doesn't inherit open windows.
+func dedupKey(urlID int64, visitorID string) string {
+	return fmt.Sprintf("dedup:%d:%s", urlID, visitorID)
+}
+
+// markRepeats flags clicks made by a visitor (IP and user agent) whose earlier click on the same link
+// started a dedup window that is still open. The window starts when the first click is processed
+// and is not extended by repeats. Clicks of visitors who asked not to be tracked are never repeats.
+func (uc *LinkUseCase) markRepeats(ctx context.Context, clicks []entity.Click) error {
+	keys := make([]string, 0, len(clicks))
+	index := make([]int, 0, len(clicks))
+
+	for i, c := range clicks {
+		if c.VisitorID == "" {
+			continue
+		}
+
+		keys = append(keys, dedupKey(c.URLID, c.VisitorID))
+		index = append(index, i)
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	first, err := uc.cache.SetIfNotExists(ctx, uc.dedupWindow, keys...)
+	if err != nil {
+		return fmt.Errorf("LinkUseCase - markRepeats - uc.cache.SetIfNotExists: %w", err)
+	}
+
+	for j, i := range index {
+		clicks[i].IsRepeat = !first[j]
+	}
+
+	return nil
+}
+
+// dropRepeats returns clicks without repeats, reusing the backing array.
+func dropRepeats(clicks []entity.Click) []entity.Click {
+	kept := clicks[:0]
+	for _, c := range clicks {
+		if !c.IsRepeat {
+			kept = append(kept, c)
+		}
+	}
+
+	return kept
+}
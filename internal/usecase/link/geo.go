@@ -0,0 +1,17 @@
+package link
+
+// locate resolves the country and region of the click IP, both are empty when GeoIP is disabled.
+func (uc *LinkUseCase) locate(ip string) (country, region string) {
+	if uc.geo == nil {
+		return "", ""
+	}
+
+	loc, err := uc.geo.Lookup(ip)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - locate - uc.geo.Lookup: %v", err)
+
+		return "", ""
+	}
+
+	return loc.Country, loc.Region
+}
@@ -0,0 +1,53 @@
+package link
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// idAllocator hands out link IDs from blocks of sequence values reserved in Postgres,
+// so creating a link doesn't cost a round trip for nextval. nextval is atomic, therefore
+// blocks reserved by different replicas never overlap. Unused IDs are lost on restart.
+type idAllocator struct {
+	mu  sync.Mutex
+	ids []int64
+
+	blockSize int
+	reserve   func(ctx context.Context, n int) ([]int64, error)
+}
+
+func newIDAllocator(blockSize int, reserve func(ctx context.Context, n int) ([]int64, error)) *idAllocator {
+	return &idAllocator{
+		blockSize: max(blockSize, 1),
+		reserve:   reserve,
+	}
+}
+
+func (a *idAllocator) Next(ctx context.Context) (int64, error) {
+	ids, err := a.NextN(ctx, 1)
+	if err != nil {
+		return 0, err
+	}
+
+	return ids[0], nil
+}
+
+func (a *idAllocator) NextN(ctx context.Context, n int) ([]int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.ids) < n {
+		ids, err := a.reserve(ctx, max(a.blockSize, n-len(a.ids)))
+		if err != nil {
+			return nil, fmt.Errorf("idAllocator - NextN - a.reserve: %w", err)
+		}
+		a.ids = append(a.ids, ids...)
+	}
+
+	ids := make([]int64, n)
+	copy(ids, a.ids)
+	a.ids = a.ids[n:]
+
+	return ids, nil
+}
@@ -4,67 +4,313 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/andreyxaxa/URL-Shortener/internal/entity"
 	"github.com/andreyxaxa/URL-Shortener/internal/repo"
 	"github.com/andreyxaxa/URL-Shortener/pkg/encoder"
+	"github.com/andreyxaxa/URL-Shortener/pkg/geoip"
 	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
 	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+	"github.com/andreyxaxa/URL-Shortener/pkg/workerpool"
 	"github.com/medama-io/go-useragent"
 )
 
+const (
+	// _maxGenerateAttempts bounds retries when generated codes collide with custom aliases
+	// or with each other (random strategy).
+	_maxGenerateAttempts = 10
+	_defaultIDBlockSize  = 100
+	// _batchChunkSize is the number of links inserted by one statement in CreateShortURLs.
+	_batchChunkSize = 500
+	// _maxBrowserVersionLength fits the clicks.browser_version column.
+	_maxBrowserVersionLength = 20
+	// _rollupStep is the span of clicks rolled up by one transaction, a long backlog is caught up in steps.
+	_rollupStep = 24 * time.Hour
+	// _anonymizeStep is the span of clicks anonymised by one transaction.
+	_anonymizeStep = 24 * time.Hour
+)
+
 type LinkUseCase struct {
-	repo  repo.LinkRepo
-	cache repo.LinkCache
+	repo   repo.LinkRepo
+	cache  repo.LinkCache
+	clicks *workerpool.Pool[entity.Click]
+	codes  encoder.CodeGenerator
+	ids    *idAllocator
+
+	idBlockSize int
+
+	visitorSalt  []byte
+	uniqueDayTTL time.Duration
+
+	// geo is nil when no GeoIP database is configured
+	geo *geoip.Locator
+
+	// dedupWindow 0 disables click deduplication
+	dedupWindow time.Duration
+	dedupMode   DedupMode
+
+	privacyMode    PrivacyMode
+	ipv4PrefixBits int
+	ipv6PrefixBits int
+	ipHashRotation time.Duration
+	honorDNT       bool
+
+	// maxStreams 0 disables live streams and click publishing
+	maxStreams     int
+	streamBuffer   int
+	streamLifetime time.Duration
+	streams        atomic.Int64
 
 	logger logger.Interface
 }
 
-func New(r repo.LinkRepo, c repo.LinkCache, l logger.Interface) *LinkUseCase {
-	return &LinkUseCase{
-		repo:   r,
-		cache:  c,
-		logger: l,
+func New(
+	r repo.LinkRepo,
+	c repo.LinkCache,
+	clicks *workerpool.Pool[entity.Click],
+	codes encoder.CodeGenerator,
+	l logger.Interface,
+	opts ...Option,
+) *LinkUseCase {
+	uc := &LinkUseCase{
+		repo:         r,
+		cache:        c,
+		clicks:       clicks,
+		codes:        codes,
+		idBlockSize:  _defaultIDBlockSize,
+		uniqueDayTTL: _defaultUniqueDayTTL,
+		dedupMode:    DedupModeFlag,
+
+		privacyMode:    PrivacyModeFull,
+		ipv4PrefixBits: _defaultIPv4PrefixBits,
+		ipv6PrefixBits: _defaultIPv6PrefixBits,
+		ipHashRotation: _defaultIPHashRotation,
+		honorDNT:       true,
+
+		maxStreams:     _defaultMaxStreams,
+		streamBuffer:   _defaultStreamBuffer,
+		streamLifetime: _defaultStreamLifetime,
+		logger:         l,
 	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(uc)
+	}
+
+	if len(uc.visitorSalt) == 0 {
+		uc.visitorSalt = randomSalt()
+		l.Warn("LinkUseCase - New: visitor salt is not set, unique visitors will be counted again after restart")
+	}
+
+	if uc.ipv4PrefixBits < 0 || uc.ipv4PrefixBits > 32 || uc.ipv6PrefixBits < 0 || uc.ipv6PrefixBits > 128 {
+		l.Warn("LinkUseCase - New: invalid IP prefix /%d, /%d, using /%d, /%d",
+			uc.ipv4PrefixBits, uc.ipv6PrefixBits, _defaultIPv4PrefixBits, _defaultIPv6PrefixBits)
+		uc.ipv4PrefixBits, uc.ipv6PrefixBits = _defaultIPv4PrefixBits, _defaultIPv6PrefixBits
+	}
+
+	if uc.ipHashRotation <= 0 {
+		uc.ipHashRotation = _defaultIPHashRotation
+	}
+
+	if uc.streamBuffer <= 0 {
+		uc.streamBuffer = _defaultStreamBuffer
+	}
+
+	if uc.streamLifetime <= 0 {
+		uc.streamLifetime = _defaultStreamLifetime
+	}
+
+	uc.ids = newIDAllocator(uc.idBlockSize, r.GetNextSequenceValues)
+
+	return uc
 }
 
-func (uc *LinkUseCase) CreateShortURL(ctx context.Context, originalURL string, customAlias string) (string, error) {
+func (uc *LinkUseCase) CreateShortURL(ctx context.Context, originalURL string, customAlias string, expiresAt *time.Time) (string, error) {
 	var shortCode string
-	var isCustom bool
 
 	if customAlias != "" {
-		err := uc.repo.ExistsByShortCode(ctx, customAlias)
-		if err == nil {
-			return "", fmt.Errorf("LinkUseCase - CreateShortURL: %w", errs.ErrAliasAlreadyTaken)
-		}
-		if !errors.Is(err, errs.ErrRecordNotFound) {
-			return "", fmt.Errorf("LinkUseCase - CreateShortURL - uc.repo.ExistsByShortCode: %w", err)
-		}
-
 		shortCode = customAlias
-		isCustom = true
 
-		err = uc.repo.CreateWithShortCode(ctx, 0, originalURL, shortCode, isCustom)
+		// uniqueness is enforced by the insert itself, so concurrent requests for one alias can't both win
+		err := uc.repo.CreateWithShortCode(ctx, entity.Link{
+			URL:       originalURL,
+			ShortCode: shortCode,
+			IsCustom:  true,
+			ExpiresAt: expiresAt,
+		})
 		if err != nil {
 			return "", fmt.Errorf("LinkUseCase - CreateShortURL - uc.repo.CreateWithShortCode: %w", err)
 		}
 	} else {
-		nextID, err := uc.repo.GetNextSequenceValue(ctx)
+		var err error
+
+		shortCode, err = uc.createGenerated(ctx, originalURL, expiresAt)
 		if err != nil {
-			return "", fmt.Errorf("LinkUseCase - CreateShortURL - uc.repo.GetNextSequenceValue: %w", err)
+			return "", fmt.Errorf("LinkUseCase - CreateShortURL - uc.createGenerated: %w", err)
 		}
+	}
 
-		shortCode = encoder.Encode(nextID)
-		isCustom = false
+	return shortCode, nil
+}
+
+// CreateShortURLs creates links in chunks. Links with ShortCode set are custom aliases.
+// Every link gets its own result, failures don't affect other links.
+func (uc *LinkUseCase) CreateShortURLs(ctx context.Context, links []entity.Link) []entity.LinkResult {
+	results := make([]entity.LinkResult, len(links))
+	for i, link := range links {
+		link.IsCustom = link.ShortCode != ""
+		results[i].Link = link
+	}
 
-		err = uc.repo.CreateWithShortCode(ctx, nextID, originalURL, shortCode, isCustom)
+	for start := 0; start < len(results); start += _batchChunkSize {
+		end := min(start+_batchChunkSize, len(results))
+		uc.createChunk(ctx, results[start:end])
+	}
+
+	return results
+}
+
+// createChunk inserts the links of results, retrying generated codes that turned out to be taken.
+// Custom aliases take their IDs from the sequence default, only generated codes reserve IDs.
+func (uc *LinkUseCase) createChunk(ctx context.Context, results []entity.LinkResult) {
+	pending := make([]int, 0, len(results))
+	for i := range results {
+		pending = append(pending, i)
+	}
+
+	for attempt := 1; len(pending) > 0; attempt++ {
+		var generated int
+		for _, i := range pending {
+			if !results[i].Link.IsCustom {
+				generated++
+			}
+		}
+
+		ids, err := uc.ids.NextN(ctx, generated)
 		if err != nil {
-			return "", fmt.Errorf("LinkUseCase - CreateShortURL - uc.repo.CreateWithShortCode: %w", err)
+			for _, i := range pending {
+				results[i].Err = fmt.Errorf("LinkUseCase - createChunk - uc.ids.NextN: %w", err)
+			}
+
+			return
+		}
+
+		var retry []int
+
+		// notInserted settles a link whose short code is taken, either in the table or earlier in the batch
+		notInserted := func(i int) {
+			switch {
+			case results[i].Link.IsCustom:
+				results[i].Err = fmt.Errorf("LinkUseCase - createChunk: %w", errs.ErrAliasAlreadyTaken)
+			case attempt == _maxGenerateAttempts:
+				results[i].Err = fmt.Errorf("LinkUseCase - createChunk: no free code after %d attempts", attempt)
+			default:
+				retry = append(retry, i)
+			}
+		}
+
+		batch := make([]entity.Link, 0, len(pending))
+		owners := make(map[string]int, len(pending))
+
+		for _, i := range pending {
+			link := &results[i].Link
+
+			if !link.IsCustom {
+				link.ID, ids = ids[0], ids[1:]
+
+				link.ShortCode, err = uc.codes.Generate(link.ID)
+				if err != nil {
+					results[i].Err = fmt.Errorf("LinkUseCase - createChunk - uc.codes.Generate: %w", err)
+
+					continue
+				}
+			}
+
+			if _, taken := owners[link.ShortCode]; taken {
+				notInserted(i)
+
+				continue
+			}
+			owners[link.ShortCode] = i
+
+			batch = append(batch, *link)
+		}
+
+		if len(batch) > 0 {
+			inserted, err := uc.repo.CreateBatch(ctx, batch)
+			if err != nil {
+				for _, i := range pending {
+					if results[i].Err == nil {
+						results[i].Err = fmt.Errorf("LinkUseCase - createChunk - uc.repo.CreateBatch: %w", err)
+					}
+				}
+
+				return
+			}
+
+			IDs := make(map[string]int64, len(inserted))
+			for _, link := range inserted {
+				IDs[link.ShortCode] = link.ID
+			}
+
+			for _, link := range batch {
+				i := owners[link.ShortCode]
+
+				ID, ok := IDs[link.ShortCode]
+				if !ok {
+					notInserted(i)
+
+					continue
+				}
+
+				results[i].Link.ID = ID
+			}
 		}
+
+		pending = retry
 	}
+}
 
-	return shortCode, nil
+// createGenerated stores the link under a code generated for the next allocated ID.
+// Custom aliases share the namespace with generated codes, so an alias (or, for random codes,
+// another link) may already hold the code: such IDs are skipped and the next one is tried.
+func (uc *LinkUseCase) createGenerated(ctx context.Context, originalURL string, expiresAt *time.Time) (string, error) {
+	for attempt := 1; ; attempt++ {
+		nextID, err := uc.ids.Next(ctx)
+		if err != nil {
+			return "", fmt.Errorf("LinkUseCase - createGenerated - uc.ids.Next: %w", err)
+		}
+
+		shortCode, err := uc.codes.Generate(nextID)
+		if err != nil {
+			return "", fmt.Errorf("LinkUseCase - createGenerated - uc.codes.Generate: %w", err)
+		}
+
+		err = uc.repo.CreateWithShortCode(ctx, entity.Link{
+			ID:        nextID,
+			URL:       originalURL,
+			ShortCode: shortCode,
+			IsCustom:  false,
+			ExpiresAt: expiresAt,
+		})
+		if err == nil {
+			return shortCode, nil
+		}
+
+		if !errors.Is(err, errs.ErrAliasAlreadyTaken) {
+			return "", fmt.Errorf("LinkUseCase - createGenerated - uc.repo.CreateWithShortCode: %w", err)
+		}
+
+		if attempt == _maxGenerateAttempts {
+			// not an alias conflict for the caller, so the sentinel is not wrapped
+			return "", fmt.Errorf("LinkUseCase - createGenerated: no free code after %d attempts: %v", attempt, err)
+		}
+
+		uc.logger.Debug("LinkUseCase - createGenerated: code %s is taken, skipping id %d", shortCode, nextID)
+	}
 }
 
 func (uc *LinkUseCase) GetOriginalURLByShortCode(ctx context.Context, shortCode string) (string, error) {
@@ -73,7 +319,6 @@ func (uc *LinkUseCase) GetOriginalURLByShortCode(ctx context.Context, shortCode
 	// check cache
 	originalURL, err := uc.cache.Get(ctx, cacheKey)
 	if err == nil {
-		// TODO: async with worker pool
 		_, err = uc.cache.IncrementWithExpiry(ctx, fmt.Sprintf("hits:1h:%s", shortCode), 1*time.Hour)
 		if err != nil {
 			uc.logger.Warn("LinkUseCase - GetOriginalURLByShortCode - uc.cache.IncrementWithExpiry: %v", err)
@@ -92,20 +337,126 @@ func (uc *LinkUseCase) GetOriginalURLByShortCode(ctx context.Context, shortCode
 	}
 
 	// check repo
-	originalURL, err = uc.repo.GetOriginalURLByShortCode(ctx, shortCode)
+	link, err := uc.repo.GetByShortCode(ctx, shortCode)
 	if err != nil {
-		return "", fmt.Errorf("LinkUseCase - GetOriginalURLByShortCode - uc.repo.GetOriginalURLByShortCode: %w", err)
+		return "", fmt.Errorf("LinkUseCase - GetOriginalURLByShortCode - uc.repo.GetByShortCode: %w", err)
+	}
+
+	now := time.Now()
+	if link.IsExpired(now) {
+		return "", fmt.Errorf("LinkUseCase - GetOriginalURLByShortCode: %w", errs.ErrLinkExpired)
 	}
 
-	// cache set
-	// TODO: async with worker pool
+	// cache set, the entry must not outlive the link
 	ttl := uc.calculateTTL(ctx, shortCode)
-	err = uc.cache.Set(ctx, cacheKey, originalURL, ttl)
+	if link.ExpiresAt != nil {
+		ttl = min(ttl, link.ExpiresAt.Sub(now))
+	}
+
+	err = uc.cache.Set(ctx, cacheKey, link.URL, ttl)
 	if err != nil {
 		uc.logger.Warn("LinkUseCase - GetOriginalURLByShortCode - uc.cache.Set : %v", err)
 	}
 
-	return originalURL, nil
+	return link.URL, nil
+}
+
+func (uc *LinkUseCase) GetLink(ctx context.Context, shortCode string) (entity.Link, error) {
+	link, err := uc.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return entity.Link{}, fmt.Errorf("LinkUseCase - GetLink - uc.repo.GetByShortCode: %w", err)
+	}
+
+	return link, nil
+}
+
+func (uc *LinkUseCase) ListLinks(ctx context.Context, limit, offset int) ([]entity.Link, int64, error) {
+	links, err := uc.repo.ListLinks(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("LinkUseCase - ListLinks - uc.repo.ListLinks: %w", err)
+	}
+
+	total, err := uc.repo.CountLinks(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("LinkUseCase - ListLinks - uc.repo.CountLinks: %w", err)
+	}
+
+	return links, total, nil
+}
+
+func (uc *LinkUseCase) UpdateLink(ctx context.Context, shortCode string, update entity.LinkUpdate) (entity.Link, error) {
+	link, err := uc.repo.GetByShortCode(ctx, shortCode)
+	if err != nil {
+		return entity.Link{}, fmt.Errorf("LinkUseCase - UpdateLink - uc.repo.GetByShortCode: %w", err)
+	}
+
+	if update.URL != nil {
+		link.URL = *update.URL
+	}
+
+	switch {
+	case update.ClearExpiry:
+		link.ExpiresAt = nil
+	case update.ExpiresAt != nil:
+		link.ExpiresAt = update.ExpiresAt
+	}
+
+	err = uc.repo.UpdateLink(ctx, link)
+	if err != nil {
+		return entity.Link{}, fmt.Errorf("LinkUseCase - UpdateLink - uc.repo.UpdateLink: %w", err)
+	}
+
+	// redirects must not serve the old destination
+	err = uc.cache.Delete(ctx, fmt.Sprintf("url:%s", shortCode))
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - UpdateLink - uc.cache.Delete: %v", err)
+	}
+
+	return link, nil
+}
+
+func (uc *LinkUseCase) DeleteLink(ctx context.Context, shortCode string) error {
+	urlID, err := uc.repo.DeleteByShortCode(ctx, shortCode)
+	if err != nil {
+		return fmt.Errorf("LinkUseCase - DeleteLink - uc.repo.DeleteByShortCode: %w", err)
+	}
+
+	err = uc.cache.Delete(ctx, linkCacheKeys(shortCode, urlID)...)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - DeleteLink - uc.cache.Delete: %v", err)
+	}
+
+	return nil
+}
+
+func (uc *LinkUseCase) ExportLinks(ctx context.Context, fn func(entity.Link) error) error {
+	err := uc.repo.StreamLinks(ctx, fn)
+	if err != nil {
+		return fmt.Errorf("LinkUseCase - ExportLinks - uc.repo.StreamLinks: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *LinkUseCase) ExportClicks(ctx context.Context, shortCode string, filter entity.AnalyticsFilter, fn func(entity.Click) error) error {
+	err := uc.repo.StreamClicks(ctx, shortCode, filter, fn)
+	if err != nil {
+		return fmt.Errorf("LinkUseCase - ExportClicks - uc.repo.StreamClicks: %w", err)
+	}
+
+	return nil
+}
+
+// linkCacheKeys returns cache keys kept for the link without expiration or with a long one.
+// Daily visitor sets and dedup windows are keyed by the link ID, a re-created short code never
+// sees them, and they expire on their own.
+func linkCacheKeys(shortCode string, urlID int64) []string {
+	return []string{
+		fmt.Sprintf("url:%s", shortCode),
+		fmt.Sprintf("hits:1h:%s", shortCode),
+		fmt.Sprintf("hits:24h:%s", shortCode),
+		visitorsKey(urlID),
+	}
 }
 
 func (uc *LinkUseCase) calculateTTL(ctx context.Context, shortCode string) time.Duration {
@@ -141,27 +492,124 @@ func (uc *LinkUseCase) calculateTTL(ctx context.Context, shortCode string) time.
 	}
 }
 
-// TODO: async with worker pool
-func (uc *LinkUseCase) TrackClick(ctx context.Context, shortCode, IP, userAgent string) error {
-	urlID, err := uc.repo.GetIDByShortCode(ctx, shortCode)
+// TrackClick enqueues the click for asynchronous processing by the click worker pool.
+func (uc *LinkUseCase) TrackClick(_ context.Context, click entity.Click) error {
+	ok := uc.clicks.Submit(entity.Click{
+		ShortCode:  click.ShortCode,
+		IP:         click.IP,
+		UserAgent:  click.UserAgent,
+		Referrer:   truncate(click.Referrer, _maxReferrerLength),
+		DoNotTrack: uc.honorDNT && click.DoNotTrack,
+		ClickedAt:  time.Now(),
+	})
+	if !ok {
+		return fmt.Errorf("LinkUseCase - TrackClick - uc.clicks.Submit: %w", errs.ErrClickQueueFull)
+	}
+
+	return nil
+}
+
+// ProcessClicks is the click worker pool handler: it resolves links, parses user agents
+// and referrers, locates IPs, flags bots and repeats, strips identifiers the privacy mode doesn't allow
+// and stores the whole batch with a single write, then publishes it to live streams.
+func (uc *LinkUseCase) ProcessClicks(ctx context.Context, clicks []entity.Click) error {
+	shortCodes := make([]string, 0, len(clicks))
+	seen := make(map[string]struct{}, len(clicks))
+	for _, c := range clicks {
+		if _, ok := seen[c.ShortCode]; !ok {
+			seen[c.ShortCode] = struct{}{}
+			shortCodes = append(shortCodes, c.ShortCode)
+		}
+	}
+
+	IDs, err := uc.repo.GetIDsByShortCodes(ctx, shortCodes)
 	if err != nil {
-		return fmt.Errorf("LinkUseCase - TrackClick - uc.repo.GetIDByShortCode: %w", err)
+		return fmt.Errorf("LinkUseCase - ProcessClicks - uc.repo.GetIDsByShortCodes: %w", err)
 	}
 
 	// parse user-agent
 	up := useragent.NewParser()
-	agent := up.Parse(userAgent)
-	device := agent.Device()
-	browser := agent.Browser()
 
-	err = uc.repo.CreateClick(ctx, urlID, IP, userAgent, device.String(), browser.String())
+	batch := make([]entity.Click, 0, len(clicks))
+	for _, c := range clicks {
+		urlID, ok := IDs[c.ShortCode]
+		if !ok {
+			// link was removed after the redirect
+			uc.logger.Warn("LinkUseCase - ProcessClicks: link %s not found, click skipped", c.ShortCode)
+
+			continue
+		}
+
+		agent := up.Parse(c.UserAgent)
+
+		c.URLID = urlID
+		c.Device = agent.Device().String()
+		c.Browser = agent.Browser().String()
+		c.BrowserVersion = truncate(agent.BrowserVersionMajor(), _maxBrowserVersionLength)
+		c.OS = agent.OS().String()
+		c.ReferrerHost = referrerHost(c.Referrer)
+		c.Country, c.Region = uc.locate(c.IP)
+		c.IsBot = isBot(agent, c.UserAgent)
+
+		if !c.DoNotTrack {
+			c.VisitorID = uc.visitorID(c.IP, c.UserAgent)
+		}
+
+		uc.applyPrivacy(&c)
+
+		batch = append(batch, c)
+	}
+
+	if uc.dedupWindow > 0 && len(batch) > 0 {
+		// without Redis every click counts as a first one
+		err = uc.markRepeats(ctx, batch)
+		if err != nil {
+			uc.logger.Warn("LinkUseCase - ProcessClicks - uc.markRepeats: %v", err)
+		}
+
+		if uc.dedupMode == DedupModeDrop {
+			batch = dropRepeats(batch)
+		}
+	}
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	_, err = uc.repo.CreateClicks(ctx, batch)
 	if err != nil {
-		return fmt.Errorf("LinkUseCase - TrackClick - uc.repo.CreateClick: %w", err)
+		return fmt.Errorf("LinkUseCase - ProcessClicks - uc.repo.CreateClicks: %w", err)
+	}
+
+	// clicks are stored, losing visitor counts must not fail the batch
+	err = uc.recordVisitors(ctx, batch)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - ProcessClicks - uc.recordVisitors: %v", err)
+	}
+
+	if uc.maxStreams > 0 {
+		err = uc.publishClicks(ctx, batch)
+		if err != nil {
+			uc.logger.Warn("LinkUseCase - ProcessClicks - uc.publishClicks: %v", err)
+		}
 	}
 
 	return nil
 }
 
+func (uc *LinkUseCase) GetClickQueueStats() entity.ClickQueueStats {
+	stats := uc.clicks.Stats()
+
+	return entity.ClickQueueStats{
+		Depth:     stats.Depth,
+		Capacity:  stats.Capacity,
+		Enqueued:  stats.Enqueued,
+		Dropped:   stats.Dropped,
+		Processed: stats.Processed,
+		Failed:    stats.Failed,
+	}
+}
+
 func (uc *LinkUseCase) ExistsByShortCode(ctx context.Context, shortCode string) error {
 	err := uc.repo.ExistsByShortCode(ctx, shortCode)
 	if err != nil {
@@ -171,30 +619,62 @@ func (uc *LinkUseCase) ExistsByShortCode(ctx context.Context, shortCode string)
 	return nil
 }
 
-func (uc *LinkUseCase) GetAnalytics(ctx context.Context, shortCode string) (entity.Analytics, error) {
-	analytics, err := uc.repo.GetAnalytics(ctx, shortCode)
+func (uc *LinkUseCase) GetAnalytics(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) (entity.Analytics, error) {
+	analytics, err := uc.repo.GetAnalytics(ctx, shortCode, filter)
 	if err != nil {
 		return entity.Analytics{}, fmt.Errorf("LinkUseCase - GetAnalytics - uc.repo.GetAnalytics: %w", err)
 	}
 
+	// visitor counts live in Redis, analytics is still served without them
+	urlID, err := uc.linkID(ctx, shortCode)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - GetAnalytics - uc.linkID: %v", err)
+
+		return analytics, nil
+	}
+
+	analytics.UniqueVisitors, err = uc.countVisitors(ctx, urlID, filter)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - GetAnalytics - uc.countVisitors: %v", err)
+	}
+
+	err = uc.fillVisitors(ctx, urlID, "day", filter, analytics.RecentClicks)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - GetAnalytics - uc.fillVisitors: %v", err)
+	}
+
 	return analytics, nil
 }
 
-func (uc *LinkUseCase) GetRecentClicks(ctx context.Context, shortCode, interval string) ([]entity.ClickByDate, error) {
-	if interval != "day" && interval != "month" {
+func (uc *LinkUseCase) GetRecentClicks(ctx context.Context, shortCode, interval string, filter entity.AnalyticsFilter) ([]entity.ClickByDate, error) {
+	switch interval {
+	case "hour", "day", "week", "month":
+	default:
 		return nil, fmt.Errorf("LinkUseCase - GetRecentClicks: %w", errs.ErrInvalidInterval)
 	}
 
-	analytics, err := uc.repo.GetRecentClicks(ctx, shortCode, interval)
+	analytics, err := uc.repo.GetRecentClicks(ctx, shortCode, interval, filter)
 	if err != nil {
 		return nil, fmt.Errorf("LinkUseCase - GetRecentClicks - uc.repo.GetRecentClicks: %w", err)
 	}
 
+	urlID, err := uc.linkID(ctx, shortCode)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - GetRecentClicks - uc.linkID: %v", err)
+
+		return analytics, nil
+	}
+
+	err = uc.fillVisitors(ctx, urlID, interval, filter, analytics)
+	if err != nil {
+		uc.logger.Warn("LinkUseCase - GetRecentClicks - uc.fillVisitors: %v", err)
+	}
+
 	return analytics, nil
 }
 
-func (uc *LinkUseCase) GetClicksByBrowser(ctx context.Context, shortCode string) ([]entity.ClickByBrowser, error) {
-	analytics, err := uc.repo.GetClicksByBrowser(ctx, shortCode)
+func (uc *LinkUseCase) GetClicksByBrowser(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBrowser, error) {
+	analytics, err := uc.repo.GetClicksByBrowser(ctx, shortCode, filter)
 	if err != nil {
 		return nil, fmt.Errorf("LinkUseCase - GetClicksByBrowser - uc.repo.GetClicksByBrowser: %w", err)
 	}
@@ -202,11 +682,137 @@ func (uc *LinkUseCase) GetClicksByBrowser(ctx context.Context, shortCode string)
 	return analytics, nil
 }
 
-func (uc *LinkUseCase) GetClicksByDevice(ctx context.Context, shortCode string) ([]entity.ClickByDevice, error) {
-	analytics, err := uc.repo.GetClicksByDevice(ctx, shortCode)
+func (uc *LinkUseCase) GetClicksByDevice(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByDevice, error) {
+	analytics, err := uc.repo.GetClicksByDevice(ctx, shortCode, filter)
 	if err != nil {
 		return nil, fmt.Errorf("LinkUseCase - GetClicksByDevice - uc.repo.GetClicksByDevice: %w", err)
 	}
 
 	return analytics, nil
 }
+
+func (uc *LinkUseCase) GetClicksByOS(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByOS, error) {
+	analytics, err := uc.repo.GetClicksByOS(ctx, shortCode, filter)
+	if err != nil {
+		return nil, fmt.Errorf("LinkUseCase - GetClicksByOS - uc.repo.GetClicksByOS: %w", err)
+	}
+
+	return analytics, nil
+}
+
+func (uc *LinkUseCase) GetClicksByReferrer(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByReferrer, error) {
+	analytics, err := uc.repo.GetClicksByReferrer(ctx, shortCode, filter)
+	if err != nil {
+		return nil, fmt.Errorf("LinkUseCase - GetClicksByReferrer - uc.repo.GetClicksByReferrer: %w", err)
+	}
+
+	return analytics, nil
+}
+
+func (uc *LinkUseCase) GetClicksByCountry(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByCountry, error) {
+	analytics, err := uc.repo.GetClicksByCountry(ctx, shortCode, filter)
+	if err != nil {
+		return nil, fmt.Errorf("LinkUseCase - GetClicksByCountry - uc.repo.GetClicksByCountry: %w", err)
+	}
+
+	return analytics, nil
+}
+
+func (uc *LinkUseCase) GetClicksByBot(ctx context.Context, shortCode string, filter entity.AnalyticsFilter) ([]entity.ClickByBot, error) {
+	analytics, err := uc.repo.GetClicksByBot(ctx, shortCode, filter)
+	if err != nil {
+		return nil, fmt.Errorf("LinkUseCase - GetClicksByBot - uc.repo.GetClicksByBot: %w", err)
+	}
+
+	return analytics, nil
+}
+
+// PurgeExpiredLinks deletes links expired before the given time in batches, their clicks are removed by cascade.
+// Returns the number of deleted links.
+func (uc *LinkUseCase) PurgeExpiredLinks(ctx context.Context, before time.Time, batchSize int) (int64, error) {
+	var total int64
+
+	for {
+		links, err := uc.repo.DeleteExpired(ctx, before, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("LinkUseCase - PurgeExpiredLinks - uc.repo.DeleteExpired: %w", err)
+		}
+
+		total += int64(len(links))
+
+		keys := make([]string, 0, len(links)*len(linkCacheKeys("", 0)))
+		for _, link := range links {
+			keys = append(keys, linkCacheKeys(link.ShortCode, link.ID)...)
+		}
+
+		err = uc.cache.Delete(ctx, keys...)
+		if err != nil {
+			uc.logger.Warn("LinkUseCase - PurgeExpiredLinks - uc.cache.Delete: %v", err)
+		}
+
+		if len(links) == 0 || len(links) < batchSize {
+			return total, nil
+		}
+	}
+}
+
+// RollupClicks brings click rollups up to now minus lag, the lag leaves time for queued clicks to be written.
+// Every run rolls up at least once, so clicks written late are counted even if the watermark stays.
+// Returns the new watermark.
+func (uc *LinkUseCase) RollupClicks(ctx context.Context, lag time.Duration) (time.Time, error) {
+	target := time.Now().Add(-lag).Truncate(time.Hour)
+
+	watermark, err := uc.repo.GetRollupWatermark(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkUseCase - RollupClicks - uc.repo.GetRollupWatermark: %w", err)
+	}
+
+	for {
+		upTo := watermark.Add(_rollupStep)
+		if upTo.After(target) {
+			upTo = target
+		}
+
+		watermark, err = uc.repo.RollupClicks(ctx, upTo)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("LinkUseCase - RollupClicks - uc.repo.RollupClicks: %w", err)
+		}
+
+		if !watermark.Before(target) {
+			return watermark, nil
+		}
+	}
+}
+
+// MaintainClickPartitions creates partitions of clicks for the current and the next ahead months
+// and, if retention is set, drops partitions older than retention. Raw clicks are dropped only after
+// they are rolled up, so analytics for old periods keeps working from rollups.
+func (uc *LinkUseCase) MaintainClickPartitions(ctx context.Context, ahead int, retention time.Duration) (created, dropped []string, err error) {
+	now := time.Now()
+
+	created, err = uc.repo.CreateClickPartitions(ctx, now, now.AddDate(0, ahead, 0))
+	if err != nil {
+		return created, nil, fmt.Errorf("LinkUseCase - MaintainClickPartitions - uc.repo.CreateClickPartitions: %w", err)
+	}
+
+	if retention <= 0 {
+		return created, nil, nil
+	}
+
+	before := now.Add(-retention)
+
+	watermark, err := uc.repo.GetRollupWatermark(ctx)
+	if err != nil {
+		return created, nil, fmt.Errorf("LinkUseCase - MaintainClickPartitions - uc.repo.GetRollupWatermark: %w", err)
+	}
+	if watermark.Before(before) {
+		before = watermark
+	}
+
+	dropped, err = uc.repo.DropClickPartitions(ctx, before)
+	if err != nil {
+		return created, dropped, fmt.Errorf("LinkUseCase - MaintainClickPartitions - uc.repo.DropClickPartitions: %w", err)
+	}
+
+	return created, dropped, nil
+}
@@ -0,0 +1,316 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/internal/repo"
+	"github.com/andreyxaxa/URL-Shortener/pkg/encoder"
+	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+)
+
+// fakeLinkRepo keeps links in memory and enforces unique short codes the way the urls table does.
+// Methods the tests don't need are left to the embedded nil interface.
+type fakeLinkRepo struct {
+	repo.LinkRepo
+
+	mu     sync.Mutex
+	links  map[string]entity.Link
+	lastID int64
+	// reserved counts IDs handed out by GetNextSequenceValues, not by the sequence default
+	reserved int
+}
+
+func newFakeLinkRepo() *fakeLinkRepo {
+	return &fakeLinkRepo{links: make(map[string]entity.Link)}
+}
+
+func (f *fakeLinkRepo) GetNextSequenceValues(_ context.Context, n int) ([]int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ids := make([]int64, 0, n)
+	for range n {
+		f.lastID++
+		ids = append(ids, f.lastID)
+	}
+	f.reserved += n
+
+	return ids, nil
+}
+
+func (f *fakeLinkRepo) CreateWithShortCode(_ context.Context, link entity.Link) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.links[link.ShortCode]; ok {
+		return fmt.Errorf("fakeLinkRepo - CreateWithShortCode: %w", errs.ErrAliasAlreadyTaken)
+	}
+
+	f.links[link.ShortCode] = link
+
+	return nil
+}
+
+func (f *fakeLinkRepo) CreateBatch(_ context.Context, links []entity.Link) ([]entity.Link, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	inserted := make([]entity.Link, 0, len(links))
+	for _, link := range links {
+		if _, ok := f.links[link.ShortCode]; ok {
+			continue
+		}
+
+		if link.IsCustom {
+			f.lastID++
+			link.ID = f.lastID
+		}
+
+		f.links[link.ShortCode] = link
+		inserted = append(inserted, entity.Link{ID: link.ID, ShortCode: link.ShortCode})
+	}
+
+	return inserted, nil
+}
+
+func newTestUseCase(r repo.LinkRepo, codes encoder.CodeGenerator) *LinkUseCase {
+	return New(r, nil, nil, codes, logger.New("error"), VisitorSalt("test"), IDBlockSize(1))
+}
+
+func TestCreateShortURLCustomAliasRace(t *testing.T) {
+	uc := newTestUseCase(newFakeLinkRepo(), encoder.NewSequential())
+
+	const callers = 2
+
+	var (
+		wg      sync.WaitGroup
+		start   = make(chan struct{})
+		results = make([]error, callers)
+	)
+
+	for i := range callers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			<-start
+			_, results[i] = uc.CreateShortURL(context.Background(), fmt.Sprintf("https://example.com/%d", i), "launch", nil)
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	var created, taken int
+	for _, err := range results {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, errs.ErrAliasAlreadyTaken):
+			taken++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if created != 1 || taken != 1 {
+		t.Fatalf("created %d, taken %d, want 1 and 1", created, taken)
+	}
+}
+
+// fakeCodes maps IDs to codes or errors, IDs without either get the fallback.
+type fakeCodes struct {
+	mu       sync.Mutex
+	codes    map[int64]string
+	errs     map[int64]error
+	fallback string
+	calls    int
+}
+
+func (f *fakeCodes) Generate(id int64) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if err, ok := f.errs[id]; ok {
+		return "", err
+	}
+
+	if code, ok := f.codes[id]; ok {
+		return code, nil
+	}
+
+	return f.fallback, nil
+}
+
+func TestCreateShortURLSkipsCodeTakenByAlias(t *testing.T) {
+	r := newFakeLinkRepo()
+	r.links["promo"] = entity.Link{URL: "https://example.com/custom", ShortCode: "promo", IsCustom: true}
+
+	uc := newTestUseCase(r, &fakeCodes{codes: map[int64]string{1: "promo", 2: "fresh"}})
+
+	shortCode, err := uc.CreateShortURL(context.Background(), "https://example.com/generated", "", nil)
+	if err != nil {
+		t.Fatalf("CreateShortURL: %v", err)
+	}
+
+	if shortCode != "fresh" {
+		t.Fatalf("short code %q, want %q", shortCode, "fresh")
+	}
+
+	if link := r.links["fresh"]; link.ID != 2 || link.URL != "https://example.com/generated" {
+		t.Fatalf("stored %+v, want id 2 with the generated url", link)
+	}
+
+	if link := r.links["promo"]; !link.IsCustom || link.URL != "https://example.com/custom" {
+		t.Fatalf("custom alias was overwritten: %+v", link)
+	}
+}
+
+func TestCreateShortURLGivesUpAfterMaxAttempts(t *testing.T) {
+	r := newFakeLinkRepo()
+	r.links["promo"] = entity.Link{URL: "https://example.com/custom", ShortCode: "promo", IsCustom: true}
+
+	codes := &fakeCodes{fallback: "promo"}
+	uc := newTestUseCase(r, codes)
+
+	_, err := uc.CreateShortURL(context.Background(), "https://example.com/generated", "", nil)
+	if err == nil {
+		t.Fatal("expected an error when every generated code is taken")
+	}
+
+	// the caller didn't ask for an alias, so this is not an alias conflict
+	if errors.Is(err, errs.ErrAliasAlreadyTaken) {
+		t.Fatalf("error wraps ErrAliasAlreadyTaken: %v", err)
+	}
+
+	if codes.calls != _maxGenerateAttempts {
+		t.Fatalf("%d attempts, want %d", codes.calls, _maxGenerateAttempts)
+	}
+}
+
+func TestCreateShortURLsRetriesTakenGeneratedCodes(t *testing.T) {
+	r := newFakeLinkRepo()
+	r.links["promo"] = entity.Link{URL: "https://example.com/custom", ShortCode: "promo", IsCustom: true}
+
+	// id 2 collides with the stored alias, id 3 with the code of id 1 from the same batch
+	uc := newTestUseCase(r, &fakeCodes{codes: map[int64]string{1: "a", 2: "promo", 3: "a", 4: "b", 5: "c"}})
+
+	results := uc.CreateShortURLs(context.Background(), []entity.Link{
+		{URL: "https://example.com/1"},
+		{URL: "https://example.com/2"},
+		{URL: "https://example.com/3"},
+	})
+
+	if code := results[0].Link.ShortCode; code != "a" {
+		t.Errorf("link 0: short code %q, want %q", code, "a")
+	}
+
+	for i, res := range results {
+		if res.Err != nil {
+			t.Fatalf("link %d: %v", i, res.Err)
+		}
+
+		if res.Link.ShortCode == "promo" {
+			t.Errorf("link %d got the taken code", i)
+		}
+
+		if stored := r.links[res.Link.ShortCode]; stored.URL != res.Link.URL || stored.ID != res.Link.ID {
+			t.Errorf("link %d: stored %+v, want %+v", i, stored, res.Link)
+		}
+	}
+
+	if len(r.links) != 4 {
+		t.Errorf("%d links stored, want 4", len(r.links))
+	}
+
+	if link := r.links["promo"]; !link.IsCustom || link.URL != "https://example.com/custom" {
+		t.Fatalf("custom alias was overwritten: %+v", link)
+	}
+}
+
+func TestCreateShortURLsGivesUpAfterMaxAttempts(t *testing.T) {
+	r := newFakeLinkRepo()
+	r.links["promo"] = entity.Link{URL: "https://example.com/custom", ShortCode: "promo", IsCustom: true}
+
+	codes := &fakeCodes{fallback: "promo"}
+	uc := newTestUseCase(r, codes)
+
+	results := uc.CreateShortURLs(context.Background(), []entity.Link{{URL: "https://example.com/generated"}})
+
+	err := results[0].Err
+	if err == nil {
+		t.Fatal("expected an error when every generated code is taken")
+	}
+
+	if errors.Is(err, errs.ErrAliasAlreadyTaken) {
+		t.Fatalf("error wraps ErrAliasAlreadyTaken: %v", err)
+	}
+
+	if codes.calls != _maxGenerateAttempts {
+		t.Fatalf("%d attempts, want %d", codes.calls, _maxGenerateAttempts)
+	}
+}
+
+func TestCreateShortURLsDuplicateAliases(t *testing.T) {
+	r := newFakeLinkRepo()
+	r.links["taken"] = entity.Link{URL: "https://example.com/old", ShortCode: "taken", IsCustom: true}
+
+	uc := newTestUseCase(r, encoder.NewSequential())
+
+	results := uc.CreateShortURLs(context.Background(), []entity.Link{
+		{URL: "https://example.com/1", ShortCode: "launch"},
+		{URL: "https://example.com/2", ShortCode: "launch"},
+		{URL: "https://example.com/3", ShortCode: "taken"},
+		{URL: "https://example.com/4", ShortCode: "sale"},
+	})
+
+	for i, wantTaken := range []bool{false, true, true, false} {
+		err := results[i].Err
+		if wantTaken != errors.Is(err, errs.ErrAliasAlreadyTaken) || (!wantTaken && err != nil) {
+			t.Errorf("link %d: error %v, want alias taken %v", i, err, wantTaken)
+		}
+	}
+
+	if link := r.links["launch"]; link.URL != "https://example.com/1" {
+		t.Errorf("launch stored %+v, want the first link of the batch", link)
+	}
+
+	if r.reserved != 0 {
+		t.Errorf("custom aliases reserved %d IDs, want none", r.reserved)
+	}
+}
+
+func TestCreateShortURLsGeneratorError(t *testing.T) {
+	r := newFakeLinkRepo()
+
+	genErr := errors.New("generator failed")
+	uc := newTestUseCase(r, &fakeCodes{codes: map[int64]string{2: "b"}, errs: map[int64]error{1: genErr}})
+
+	results := uc.CreateShortURLs(context.Background(), []entity.Link{
+		{URL: "https://example.com/1"},
+		{URL: "https://example.com/2"},
+		{URL: "https://example.com/3", ShortCode: "launch"},
+	})
+
+	if !errors.Is(results[0].Err, genErr) {
+		t.Errorf("link 0: error %v, want the generator error", results[0].Err)
+	}
+
+	for i, want := range map[int]string{1: "b", 2: "launch"} {
+		if results[i].Err != nil || results[i].Link.ShortCode != want {
+			t.Errorf("link %d: %+v, want short code %q", i, results[i], want)
+		}
+	}
+
+	if r.reserved != 2 {
+		t.Errorf("reserved %d IDs, want 2 for the generated codes", r.reserved)
+	}
+}
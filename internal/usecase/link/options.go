@@ -0,0 +1,73 @@
+package link
+
+import (
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/pkg/geoip"
+)
+
+type Option func(*LinkUseCase)
+
+func IDBlockSize(size int) Option {
+	return func(uc *LinkUseCase) {
+		uc.idBlockSize = size
+	}
+}
+
+// VisitorSalt is mixed into visitor hashes, set it to keep unique counts stable across restarts.
+func VisitorSalt(salt string) Option {
+	return func(uc *LinkUseCase) {
+		uc.visitorSalt = []byte(salt)
+	}
+}
+
+func UniqueDayTTL(ttl time.Duration) Option {
+	return func(uc *LinkUseCase) {
+		uc.uniqueDayTTL = ttl
+	}
+}
+
+// GeoIP enables country and region lookup of clicks, nil keeps it disabled.
+func GeoIP(geo *geoip.Locator) Option {
+	return func(uc *LinkUseCase) {
+		uc.geo = geo
+	}
+}
+
+// Dedup marks clicks repeated by the same visitor on the same link within window and, depending on mode,
+// stores them flagged or drops them. Window 0 disables deduplication.
+func Dedup(window time.Duration, mode DedupMode) Option {
+	return func(uc *LinkUseCase) {
+		uc.dedupWindow = window
+		uc.dedupMode = mode
+	}
+}
+
+// Privacy sets how client IPs are stored: as they are, truncated to ipv4Bits/ipv6Bits networks
+// or hashed with a key rotated every hashRotation.
+func Privacy(mode PrivacyMode, ipv4Bits, ipv6Bits int, hashRotation time.Duration) Option {
+	return func(uc *LinkUseCase) {
+		uc.privacyMode = mode
+		uc.ipv4PrefixBits = ipv4Bits
+		uc.ipv6PrefixBits = ipv6Bits
+		uc.ipHashRotation = hashRotation
+	}
+}
+
+// HonorDoNotTrack makes clicks with DNT or Sec-GPC headers stored without identifiers.
+func HonorDoNotTrack(honor bool) Option {
+	return func(uc *LinkUseCase) {
+		uc.honorDNT = honor
+	}
+}
+
+// Stream limits live click streams of this replica: at most maxConnections open at once (0 disables streams
+// and publishing), buffer events queued per connection before events are dropped,
+// and a lifetime after which a connection is closed.
+func Stream(maxConnections, buffer int, lifetime time.Duration) Option {
+	return func(uc *LinkUseCase) {
+		uc.maxStreams = maxConnections
+		uc.streamBuffer = buffer
+		uc.streamLifetime = lifetime
+	}
+}
@@ -0,0 +1,127 @@
+package link
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+)
+
+// PrivacyMode tells how client IPs are stored.
+type PrivacyMode string
+
+const (
+	// PrivacyModeFull stores IPs and user agents as they are.
+	PrivacyModeFull PrivacyMode = "full"
+	// PrivacyModeTruncate stores the network of the IP (ipv4Bits/ipv6Bits prefix) and no raw user agent.
+	PrivacyModeTruncate PrivacyMode = "truncate"
+	// PrivacyModeHash stores a keyed hash of the IP instead of the IP and no raw user agent,
+	// the key changes every rotation period, so hashes can't be linked across periods.
+	PrivacyModeHash PrivacyMode = "hash"
+)
+
+const (
+	_defaultIPv4PrefixBits = 24
+	_defaultIPv6PrefixBits = 48
+	_defaultIPHashRotation = 24 * time.Hour
+)
+
+func ParsePrivacyMode(mode string) (PrivacyMode, error) {
+	switch PrivacyMode(mode) {
+	case PrivacyModeFull, PrivacyModeTruncate, PrivacyModeHash:
+		return PrivacyMode(mode), nil
+	default:
+		return "", fmt.Errorf("unknown privacy mode %q: must be %s, %s or %s",
+			mode, PrivacyModeFull, PrivacyModeTruncate, PrivacyModeHash)
+	}
+}
+
+// truncateIP returns the network of ip with the prefix length, e.g. 203.0.113.0/24, "" if ip is not an address.
+func truncateIP(ip string, ipv4Bits, ipv6Bits int) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+
+	addr = addr.Unmap()
+
+	bits := ipv6Bits
+	if addr.Is4() {
+		bits = ipv4Bits
+	}
+
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return ""
+	}
+
+	return prefix.String()
+}
+
+// ipHash hashes ip with a key derived from the visitor salt and the rotation period t falls into.
+func (uc *LinkUseCase) ipHash(ip string, t time.Time) string {
+	period := make([]byte, 8)
+	binary.BigEndian.PutUint64(period, uint64(t.UnixNano()/int64(uc.ipHashRotation)))
+
+	key := hmac.New(sha256.New, uc.visitorSalt)
+	key.Write(period)
+
+	h := hmac.New(sha256.New, key.Sum(nil))
+	h.Write([]byte(ip))
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// applyPrivacy removes identifiers the click must not be stored with. It runs after everything
+// derived from them (visitor, location, parsed user agent, referrer host) is filled in.
+// Only the full mode keeps the full referrer, it may carry identifiers in its query.
+func (uc *LinkUseCase) applyPrivacy(c *entity.Click) {
+	if c.DoNotTrack {
+		c.IP, c.IPHash, c.UserAgent, c.Referrer = "", "", "", ""
+
+		return
+	}
+
+	switch uc.privacyMode {
+	case PrivacyModeTruncate:
+		c.IP = truncateIP(c.IP, uc.ipv4PrefixBits, uc.ipv6PrefixBits)
+		c.UserAgent, c.Referrer = "", ""
+	case PrivacyModeHash:
+		if c.IP != "" {
+			c.IPHash = uc.ipHash(c.IP, c.ClickedAt)
+		}
+		c.IP = ""
+		c.UserAgent, c.Referrer = "", ""
+	}
+}
+
+// AnonymizeClicks removes identifiers of clicks older than age: IPs are truncated to networks,
+// IP hashes, user agents and full referrers are cleared. Returns the new watermark.
+func (uc *LinkUseCase) AnonymizeClicks(ctx context.Context, age time.Duration) (time.Time, error) {
+	target := time.Now().Add(-age).Truncate(time.Hour)
+
+	watermark, err := uc.repo.GetAnonymizeWatermark(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("LinkUseCase - AnonymizeClicks - uc.repo.GetAnonymizeWatermark: %w", err)
+	}
+
+	for watermark.Before(target) {
+		upTo := watermark.Add(_anonymizeStep)
+		if upTo.After(target) {
+			upTo = target
+		}
+
+		watermark, err = uc.repo.AnonymizeClicks(ctx, upTo, uc.ipv4PrefixBits, uc.ipv6PrefixBits)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("LinkUseCase - AnonymizeClicks - uc.repo.AnonymizeClicks: %w", err)
+		}
+	}
+
+	return watermark, nil
+}
@@ -0,0 +1,140 @@
+package link
+
+import (
+	"testing"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+)
+
+func TestApplyPrivacyKeepsFullReferrerOnlyInFullMode(t *testing.T) {
+	for _, mode := range []PrivacyMode{PrivacyModeFull, PrivacyModeTruncate, PrivacyModeHash} {
+		uc := newTestUseCase(newFakeLinkRepo(), nil)
+		uc.privacyMode = mode
+
+		c := entity.Click{
+			IP:           "203.0.113.7",
+			UserAgent:    "Mozilla/5.0",
+			Referrer:     "https://news.example.com/post?utm_source=mail&uid=42",
+			ReferrerHost: "news.example.com",
+			ClickedAt:    time.Now(),
+		}
+		uc.applyPrivacy(&c)
+
+		if keep := mode == PrivacyModeFull; (c.Referrer != "") != keep {
+			t.Errorf("%s: referrer %q, want kept %v", mode, c.Referrer, keep)
+		}
+
+		if c.ReferrerHost != "news.example.com" {
+			t.Errorf("%s: referrer host %q, want it kept", mode, c.ReferrerHost)
+		}
+	}
+}
+
+func TestTruncateIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		ipv4Bits int
+		ipv6Bits int
+		want     string
+	}{
+		{"203.0.113.7", 24, 48, "203.0.113.0/24"},
+		{"203.0.113.7", 16, 48, "203.0.0.0/16"},
+		{"203.0.113.7", 32, 48, "203.0.113.7/32"},
+		{"203.0.113.7", 0, 48, "0.0.0.0/0"},
+		{"::ffff:203.0.113.7", 24, 48, "203.0.113.0/24"},
+		{"2001:db8:1234:5678::1", 24, 48, "2001:db8:1234::/48"},
+		{"2001:db8:1234:5678::1", 24, 64, "2001:db8:1234:5678::/64"},
+		{"2001:db8:1234:5678::1", 24, 128, "2001:db8:1234:5678::1/128"},
+		{"203.0.113.7", 33, 48, ""},
+		{"not-an-ip", 24, 48, ""},
+		{"", 24, 48, ""},
+	}
+
+	for _, tt := range tests {
+		got := truncateIP(tt.ip, tt.ipv4Bits, tt.ipv6Bits)
+		if got != tt.want {
+			t.Errorf("truncateIP(%q, %d, %d) = %q, want %q", tt.ip, tt.ipv4Bits, tt.ipv6Bits, got, tt.want)
+		}
+	}
+}
+
+func TestApplyPrivacyTruncateMode(t *testing.T) {
+	uc := newTestUseCase(newFakeLinkRepo(), nil)
+	uc.privacyMode = PrivacyModeTruncate
+
+	c := entity.Click{IP: "2001:db8:1234:5678::1", UserAgent: "Mozilla/5.0", ClickedAt: time.Now()}
+	uc.applyPrivacy(&c)
+
+	if c.IP != "2001:db8:1234::/48" || c.IPHash != "" || c.UserAgent != "" {
+		t.Errorf("stored %+v, want the /48 network only", c)
+	}
+}
+
+func TestApplyPrivacyHashMode(t *testing.T) {
+	uc := newTestUseCase(newFakeLinkRepo(), nil)
+	uc.privacyMode = PrivacyModeHash
+
+	now := time.Now()
+
+	hash := func(ip string, at time.Time) string {
+		t.Helper()
+
+		c := entity.Click{IP: ip, UserAgent: "Mozilla/5.0", ClickedAt: at}
+		uc.applyPrivacy(&c)
+
+		if c.IP != "" || c.UserAgent != "" {
+			t.Fatalf("stored %+v, want no IP and user agent", c)
+		}
+
+		return c.IPHash
+	}
+
+	h := hash("203.0.113.7", now)
+	if len(h) != 32 {
+		t.Fatalf("hash %q, want 32 hex characters", h)
+	}
+
+	if hash("203.0.113.7", now) != h {
+		t.Error("same IP in the same period gives another hash")
+	}
+
+	if hash("203.0.113.8", now) == h {
+		t.Error("another IP gives the same hash")
+	}
+
+	if hash("203.0.113.7", now.Add(uc.ipHashRotation)) == h {
+		t.Error("the hash doesn't change with the rotation period")
+	}
+
+	if other := hash("", now); other != "" {
+		t.Errorf("click without IP got hash %q", other)
+	}
+}
+
+func TestApplyPrivacyDoNotTrack(t *testing.T) {
+	for _, mode := range []PrivacyMode{PrivacyModeFull, PrivacyModeTruncate, PrivacyModeHash} {
+		uc := newTestUseCase(newFakeLinkRepo(), nil)
+		uc.privacyMode = mode
+
+		c := entity.Click{
+			IP:           "203.0.113.7",
+			UserAgent:    "Mozilla/5.0",
+			DoNotTrack:   true,
+			Browser:      "Chrome",
+			Referrer:     "https://news.example.com/post",
+			ReferrerHost: "news.example.com",
+			Country:      "GB",
+			ClickedAt:    time.Now(),
+		}
+		uc.applyPrivacy(&c)
+
+		if c.IP != "" || c.IPHash != "" || c.UserAgent != "" || c.Referrer != "" {
+			t.Errorf("%s: stored %+v, want no identifiers", mode, c)
+		}
+
+		if c.Browser != "Chrome" || c.ReferrerHost != "news.example.com" || c.Country != "GB" {
+			t.Errorf("%s: stored %+v, want derived fields kept", mode, c)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package link
+
+import (
+	"net/url"
+	"strings"
+)
+
+const (
+	_maxReferrerLength     = 2048
+	_maxReferrerHostLength = 255
+)
+
+// referrerHost normalises the Referer header to a lower-case host without port and "www.",
+// so links shared from www.twitter.com and twitter.com land in one group. Returns "" for direct visits
+// and values that are not absolute URLs.
+func referrerHost(referrer string) string {
+	u, err := url.Parse(strings.TrimSpace(referrer))
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimSuffix(host, ".")
+	host = strings.TrimPrefix(host, "www.")
+
+	if len(host) > _maxReferrerHostLength {
+		return ""
+	}
+
+	return host
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	// keep the result valid UTF-8
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,107 @@
+package link
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+)
+
+const (
+	_defaultMaxStreams     = 100
+	_defaultStreamBuffer   = 64
+	_defaultStreamLifetime = time.Hour
+)
+
+// streamChannel is the Pub/Sub channel clicks of the link are published to by every replica.
+func streamChannel(shortCode string) string {
+	return fmt.Sprintf("clicks:%s", shortCode)
+}
+
+// publishClicks publishes stored clicks to live streams of their links.
+func (uc *LinkUseCase) publishClicks(ctx context.Context, clicks []entity.Click) error {
+	messages := make(map[string][]string)
+
+	for _, c := range clicks {
+		event, err := json.Marshal(entity.ClickEvent{
+			ShortCode: c.ShortCode,
+			Browser:   c.Browser,
+			Device:    c.Device,
+			Country:   c.Country,
+			IsBot:     c.IsBot,
+			ClickedAt: c.ClickedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("LinkUseCase - publishClicks - json.Marshal: %w", err)
+		}
+
+		channel := streamChannel(c.ShortCode)
+		messages[channel] = append(messages[channel], string(event))
+	}
+
+	err := uc.cache.Publish(ctx, messages)
+	if err != nil {
+		return fmt.Errorf("LinkUseCase - publishClicks - uc.cache.Publish: %w", err)
+	}
+
+	return nil
+}
+
+// SubscribeClicks streams clicks of the link tracked by any replica from now on, until ctx is done
+// or the stream lifetime is over, then the returned channel is closed.
+// A subscriber that falls behind by more than the stream buffer loses events,
+// the next delivered event tells how many were missed.
+// Returns errs.ErrStreamLimit when all stream slots of this replica are taken.
+func (uc *LinkUseCase) SubscribeClicks(ctx context.Context, shortCode string) (<-chan entity.ClickEvent, error) {
+	if uc.streams.Add(1) > int64(uc.maxStreams) {
+		uc.streams.Add(-1)
+
+		return nil, fmt.Errorf("LinkUseCase - SubscribeClicks: %w", errs.ErrStreamLimit)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, uc.streamLifetime)
+
+	msgs, err := uc.cache.Subscribe(ctx, streamChannel(shortCode))
+	if err != nil {
+		cancel()
+		uc.streams.Add(-1)
+
+		return nil, fmt.Errorf("LinkUseCase - SubscribeClicks - uc.cache.Subscribe: %w", err)
+	}
+
+	events := make(chan entity.ClickEvent, uc.streamBuffer)
+
+	go func() {
+		defer uc.streams.Add(-1)
+		defer cancel()
+		defer close(events)
+
+		var missed int64
+
+		for msg := range msgs {
+			var event entity.ClickEvent
+
+			err := json.Unmarshal([]byte(msg), &event)
+			if err != nil {
+				uc.logger.Warn("LinkUseCase - SubscribeClicks - json.Unmarshal: %v", err)
+
+				continue
+			}
+
+			event.Missed = missed
+
+			// never wait for the subscriber, the Redis connection would stall behind it
+			select {
+			case events <- event:
+				missed = 0
+			default:
+				missed++
+			}
+		}
+	}()
+
+	return events, nil
+}
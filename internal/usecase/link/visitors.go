@@ -0,0 +1,195 @@
+package link
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/internal/entity"
+	"github.com/andreyxaxa/URL-Shortener/pkg/types/errs"
+)
+
+const (
+	_visitorDayLayout = "2006-01-02"
+	// _defaultUniqueDayTTL keeps daily visitor sets long enough for the month series
+	_defaultUniqueDayTTL = 400 * 24 * time.Hour
+)
+
+// visitorsKey is the all-time HyperLogLog of visitors of the link. Visitor sets are keyed by the link ID,
+// so a deleted and re-created short code starts without visitors.
+func visitorsKey(urlID int64) string {
+	return fmt.Sprintf("visitors:%d", urlID)
+}
+
+// visitorsDayKey is the HyperLogLog of visitors of the link during a UTC day.
+func visitorsDayKey(urlID int64, day time.Time) string {
+	return fmt.Sprintf("visitors:%d:%s", urlID, day.UTC().Format(_visitorDayLayout))
+}
+
+// linkID resolves the ID visitor sets of the link are keyed by.
+func (uc *LinkUseCase) linkID(ctx context.Context, shortCode string) (int64, error) {
+	IDs, err := uc.repo.GetIDsByShortCodes(ctx, []string{shortCode})
+	if err != nil {
+		return 0, fmt.Errorf("LinkUseCase - linkID - uc.repo.GetIDsByShortCodes: %w", err)
+	}
+
+	urlID, ok := IDs[shortCode]
+	if !ok {
+		return 0, fmt.Errorf("LinkUseCase - linkID: %w", errs.ErrRecordNotFound)
+	}
+
+	return urlID, nil
+}
+
+// visitorID identifies a visitor by IP and user agent, salted so the raw IP can't be recovered from Redis.
+func (uc *LinkUseCase) visitorID(ip, userAgent string) string {
+	h := sha256.New()
+	h.Write(uc.visitorSalt)
+	h.Write([]byte(ip))
+	h.Write([]byte{0})
+	h.Write([]byte(userAgent))
+
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func randomSalt() []byte {
+	salt := make([]byte, 32)
+	_, _ = rand.Read(salt)
+
+	return salt
+}
+
+// recordVisitors adds visitors of stored clicks to the all-time and daily sets of their links.
+// Sets can't be filtered later, so bots are never added, neither are visitors who asked not to be tracked.
+func (uc *LinkUseCase) recordVisitors(ctx context.Context, clicks []entity.Click) error {
+	members := make(map[string][]string)
+	daily := make(map[string]bool)
+
+	for _, c := range clicks {
+		if c.IsBot || c.VisitorID == "" {
+			continue
+		}
+
+		id := c.VisitorID
+
+		members[visitorsKey(c.URLID)] = append(members[visitorsKey(c.URLID)], id)
+
+		dayKey := visitorsDayKey(c.URLID, c.ClickedAt)
+		members[dayKey] = append(members[dayKey], id)
+		daily[dayKey] = true
+	}
+
+	for key, ids := range members {
+		var ttl time.Duration
+		if daily[key] {
+			ttl = uc.uniqueDayTTL
+		}
+
+		err := uc.cache.AddUnique(ctx, key, ttl, ids...)
+		if err != nil {
+			return fmt.Errorf("LinkUseCase - recordVisitors - uc.cache.AddUnique: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// visitorDayKeys returns daily set keys of the link for UTC days overlapping [from, to),
+// days beyond the set TTL are skipped as they are gone anyway.
+func (uc *LinkUseCase) visitorDayKeys(urlID int64, from, to time.Time) []string {
+	oldest := time.Now().Add(-uc.uniqueDayTTL)
+	if from.Before(oldest) {
+		from = oldest
+	}
+
+	keys := make([]string, 0)
+	for d := from.UTC().Truncate(24 * time.Hour); d.Before(to); d = d.Add(24 * time.Hour) {
+		keys = append(keys, visitorsDayKey(urlID, d))
+	}
+
+	return keys
+}
+
+// countVisitors returns unique visitors of the link in the filter range: all-time without a range,
+// otherwise the union of the UTC days it overlaps.
+func (uc *LinkUseCase) countVisitors(ctx context.Context, urlID int64, filter entity.AnalyticsFilter) (int64, error) {
+	keys := []string{visitorsKey(urlID)}
+
+	if filter.From != nil || filter.To != nil {
+		from := time.Now().Add(-uc.uniqueDayTTL)
+		if filter.From != nil {
+			from = *filter.From
+		}
+
+		to := time.Now()
+		if filter.To != nil && filter.To.Before(to) {
+			to = *filter.To
+		}
+
+		keys = uc.visitorDayKeys(urlID, from, to)
+		if len(keys) == 0 {
+			return 0, nil
+		}
+	}
+
+	counts, err := uc.cache.CountUnique(ctx, keys)
+	if err != nil {
+		return 0, fmt.Errorf("LinkUseCase - countVisitors - uc.cache.CountUnique: %w", err)
+	}
+
+	return counts[0], nil
+}
+
+// fillVisitors sets unique visitors of day, week and month buckets. Visitor sets are kept per UTC day,
+// so buckets in other time zones and hourly buckets are left without the count.
+func (uc *LinkUseCase) fillVisitors(ctx context.Context, urlID int64, interval string, filter entity.AnalyticsFilter,
+	series []entity.ClickByDate,
+) error {
+	if filter.TZ().String() != "UTC" || len(series) == 0 {
+		return nil
+	}
+
+	var next func(time.Time) time.Time
+
+	switch interval {
+	case "day":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "week":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case "month":
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil
+	}
+
+	groups := make([][]string, 0, len(series))
+	index := make([]int, 0, len(series))
+
+	for i, b := range series {
+		keys := uc.visitorDayKeys(urlID, b.Date, next(b.Date))
+		if len(keys) == 0 {
+			continue
+		}
+
+		groups = append(groups, keys)
+		index = append(index, i)
+	}
+
+	if len(groups) == 0 {
+		return nil
+	}
+
+	counts, err := uc.cache.CountUnique(ctx, groups...)
+	if err != nil {
+		return fmt.Errorf("LinkUseCase - fillVisitors - uc.cache.CountUnique: %w", err)
+	}
+
+	for j, i := range index {
+		series[i].UniqueVisitors = &counts[j]
+	}
+
+	return nil
+}
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_urls_expires_at;
+ALTER TABLE urls DROP COLUMN IF EXISTS expires_at;
\ No newline at end of file
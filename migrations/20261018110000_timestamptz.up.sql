@@ -0,0 +1,3 @@
+-- existing values were written in UTC (server and container time zone)
+ALTER TABLE clicks ALTER COLUMN clicked_at TYPE TIMESTAMPTZ USING clicked_at AT TIME ZONE 'UTC';
+ALTER TABLE urls ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS click_rollup_watermark;
+DROP TABLE IF EXISTS click_rollups_daily;
+DROP TABLE IF EXISTS click_rollups_hourly;
\ No newline at end of file
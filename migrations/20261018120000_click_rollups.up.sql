@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS click_rollups_hourly
+(
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    bucket TIMESTAMPTZ NOT NULL,
+    browser_family VARCHAR(50) NOT NULL,
+    device VARCHAR(30) NOT NULL,
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (url_id, bucket, browser_family, device)
+);
+
+CREATE TABLE IF NOT EXISTS click_rollups_daily
+(
+    url_id BIGINT NOT NULL REFERENCES urls(id) ON DELETE CASCADE,
+    bucket TIMESTAMPTZ NOT NULL,
+    browser_family VARCHAR(50) NOT NULL,
+    device VARCHAR(30) NOT NULL,
+    clicks BIGINT NOT NULL,
+    PRIMARY KEY (url_id, bucket, browser_family, device)
+);
+
+-- clicks before rolled_up_to are in the rollups: hours in click_rollups_hourly,
+-- whole UTC days in click_rollups_daily
+CREATE TABLE IF NOT EXISTS click_rollup_watermark
+(
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    rolled_up_to TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO click_rollup_watermark (rolled_up_to)
+SELECT date_trunc('hour', COALESCE(MIN(clicked_at), now()) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+FROM clicks
+ON CONFLICT DO NOTHING;
\ No newline at end of file
@@ -0,0 +1,24 @@
+CREATE TABLE clicks_plain
+(
+    id BIGINT PRIMARY KEY DEFAULT nextval('clicks_id_seq'),
+    url_id BIGINT REFERENCES urls(id) ON DELETE CASCADE,
+    ip_address INET,
+    user_agent TEXT NOT NULL,
+    device VARCHAR(30) NOT NULL,
+    browser_family VARCHAR(50) NOT NULL,
+    clicked_at TIMESTAMPTZ DEFAULT now()
+);
+
+INSERT INTO clicks_plain (id, url_id, ip_address, user_agent, device, browser_family, clicked_at)
+SELECT id, url_id, ip_address, user_agent, device, browser_family, clicked_at
+FROM clicks;
+
+ALTER SEQUENCE clicks_id_seq OWNED BY NONE;
+DROP TABLE clicks;
+ALTER TABLE clicks_plain RENAME TO clicks;
+ALTER SEQUENCE clicks_id_seq OWNED BY clicks.id;
+
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id ON clicks(url_id);
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at ON clicks(clicked_at);
+CREATE INDEX IF NOT EXISTS idx_clicks_browser_family ON clicks(browser_family);
+CREATE INDEX IF NOT EXISTS idx_clicks_device ON clicks(device);
\ No newline at end of file
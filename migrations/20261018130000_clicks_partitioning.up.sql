@@ -0,0 +1,54 @@
+-- clicks become range partitioned by month, partitions are named clicks_pYYYY_MM (UTC months)
+ALTER TABLE clicks RENAME TO clicks_old;
+ALTER SEQUENCE clicks_id_seq OWNED BY NONE;
+
+DROP INDEX IF EXISTS idx_clicks_url_id;
+DROP INDEX IF EXISTS idx_clicks_clicked_at;
+DROP INDEX IF EXISTS idx_clicks_browser_family;
+DROP INDEX IF EXISTS idx_clicks_device;
+
+CREATE TABLE clicks
+(
+    id BIGINT NOT NULL DEFAULT nextval('clicks_id_seq'),
+    url_id BIGINT REFERENCES urls(id) ON DELETE CASCADE,
+    ip_address INET,
+    user_agent TEXT NOT NULL,
+    device VARCHAR(30) NOT NULL,
+    browser_family VARCHAR(50) NOT NULL,
+    clicked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (id, clicked_at)
+) PARTITION BY RANGE (clicked_at);
+
+ALTER SEQUENCE clicks_id_seq OWNED BY clicks.id;
+
+-- per-link lookups by time and the cascade from urls
+CREATE INDEX IF NOT EXISTS idx_clicks_url_id_clicked_at ON clicks(url_id, clicked_at);
+-- clicks are appended in time order, BRIN keeps range scans of rollups cheap
+CREATE INDEX IF NOT EXISTS idx_clicks_clicked_at_brin ON clicks USING BRIN (clicked_at);
+
+-- partitions from the first click up to two months ahead
+DO $$
+DECLARE
+    m TIMESTAMPTZ;
+BEGIN
+    -- month arithmetic on timestamptz follows the session zone
+    PERFORM set_config('timezone', 'UTC', true);
+
+    m := date_trunc('month', COALESCE((SELECT MIN(clicked_at) FROM clicks_old), now()) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+    WHILE m <= date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + INTERVAL '2 months' LOOP
+        EXECUTE format(
+            'CREATE TABLE IF NOT EXISTS %I PARTITION OF clicks FOR VALUES FROM (%L) TO (%L)',
+            'clicks_p' || to_char(m AT TIME ZONE 'UTC', 'YYYY_MM'),
+            m,
+            m + INTERVAL '1 month'
+        );
+        m := m + INTERVAL '1 month';
+    END LOOP;
+END
+$$;
+
+INSERT INTO clicks (id, url_id, ip_address, user_agent, device, browser_family, clicked_at)
+SELECT id, url_id, ip_address, user_agent, device, browser_family, COALESCE(clicked_at, now())
+FROM clicks_old;
+
+DROP TABLE clicks_old;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS referrer TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS referrer_host VARCHAR(255) NOT NULL DEFAULT '';
+
+-- referring host is a rollup dimension, existing rows are counted as direct traffic
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS referrer_host VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS referrer_host VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host);
\ No newline at end of file
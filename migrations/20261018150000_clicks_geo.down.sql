@@ -0,0 +1,28 @@
+-- rows that differed only by country are summed back
+CREATE TEMP TABLE click_rollups_daily_merged AS
+SELECT url_id, bucket, browser_family, device, referrer_host, SUM(clicks)::bigint AS clicks
+FROM click_rollups_daily
+GROUP BY url_id, bucket, browser_family, device, referrer_host;
+
+TRUNCATE click_rollups_daily;
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily DROP COLUMN IF EXISTS country;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host);
+INSERT INTO click_rollups_daily (url_id, bucket, browser_family, device, referrer_host, clicks)
+SELECT url_id, bucket, browser_family, device, referrer_host, clicks FROM click_rollups_daily_merged;
+DROP TABLE click_rollups_daily_merged;
+
+CREATE TEMP TABLE click_rollups_hourly_merged AS
+SELECT url_id, bucket, browser_family, device, referrer_host, SUM(clicks)::bigint AS clicks
+FROM click_rollups_hourly
+GROUP BY url_id, bucket, browser_family, device, referrer_host;
+
+TRUNCATE click_rollups_hourly;
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly DROP COLUMN IF EXISTS country;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host);
+INSERT INTO click_rollups_hourly (url_id, bucket, browser_family, device, referrer_host, clicks)
+SELECT url_id, bucket, browser_family, device, referrer_host, clicks FROM click_rollups_hourly_merged;
+DROP TABLE click_rollups_hourly_merged;
+
+ALTER TABLE clicks DROP COLUMN IF EXISTS region, DROP COLUMN IF EXISTS country;
\ No newline at end of file
@@ -0,0 +1,12 @@
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS region VARCHAR(3) NOT NULL DEFAULT '';
+
+-- country is a rollup dimension, existing rows are counted as unknown country
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country);
\ No newline at end of file
@@ -0,0 +1,28 @@
+-- rows that differed only by the bot flag are summed back
+CREATE TEMP TABLE click_rollups_daily_merged AS
+SELECT url_id, bucket, browser_family, device, referrer_host, country, SUM(clicks)::bigint AS clicks
+FROM click_rollups_daily
+GROUP BY url_id, bucket, browser_family, device, referrer_host, country;
+
+TRUNCATE click_rollups_daily;
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily DROP COLUMN IF EXISTS is_bot;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country);
+INSERT INTO click_rollups_daily (url_id, bucket, browser_family, device, referrer_host, country, clicks)
+SELECT url_id, bucket, browser_family, device, referrer_host, country, clicks FROM click_rollups_daily_merged;
+DROP TABLE click_rollups_daily_merged;
+
+CREATE TEMP TABLE click_rollups_hourly_merged AS
+SELECT url_id, bucket, browser_family, device, referrer_host, country, SUM(clicks)::bigint AS clicks
+FROM click_rollups_hourly
+GROUP BY url_id, bucket, browser_family, device, referrer_host, country;
+
+TRUNCATE click_rollups_hourly;
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly DROP COLUMN IF EXISTS is_bot;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country);
+INSERT INTO click_rollups_hourly (url_id, bucket, browser_family, device, referrer_host, country, clicks)
+SELECT url_id, bucket, browser_family, device, referrer_host, country, clicks FROM click_rollups_hourly_merged;
+DROP TABLE click_rollups_hourly_merged;
+
+ALTER TABLE clicks DROP COLUMN IF EXISTS is_bot;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT false;
+
+-- is_bot is a rollup dimension
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country, is_bot);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS is_bot BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country, is_bot);
+
+-- earlier clicks are flagged by the bot device the user agent parser already stored,
+-- device is a rollup dimension too, so rollups stay in line with raw clicks
+UPDATE clicks SET is_bot = true WHERE device = 'Bot';
+UPDATE click_rollups_hourly SET is_bot = true WHERE device = 'Bot';
+UPDATE click_rollups_daily SET is_bot = true WHERE device = 'Bot';
\ No newline at end of file
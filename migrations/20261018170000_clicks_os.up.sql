@@ -0,0 +1,12 @@
+ALTER TABLE clicks
+    ADD COLUMN IF NOT EXISTS os VARCHAR(30) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS browser_version VARCHAR(20) NOT NULL DEFAULT '';
+
+-- os is a rollup dimension, earlier clicks are counted as unknown OS
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS os VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country, is_bot, os);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS os VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country, is_bot, os);
\ No newline at end of file
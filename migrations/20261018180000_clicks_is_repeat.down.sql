@@ -0,0 +1,3 @@
+ALTER TABLE click_rollups_daily DROP COLUMN IF EXISTS repeat_clicks;
+ALTER TABLE click_rollups_hourly DROP COLUMN IF EXISTS repeat_clicks;
+ALTER TABLE clicks DROP COLUMN IF EXISTS is_repeat;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS is_repeat BOOLEAN NOT NULL DEFAULT false;
+
+-- repeat clicks are a measure next to clicks, not a dimension
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS repeat_clicks BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS repeat_clicks BIGINT NOT NULL DEFAULT 0;
\ No newline at end of file
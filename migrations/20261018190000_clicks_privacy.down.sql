@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS click_anonymize_watermark;
+
+ALTER TABLE clicks DROP COLUMN IF EXISTS ip_hash;
\ No newline at end of file
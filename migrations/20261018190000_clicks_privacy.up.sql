@@ -0,0 +1,13 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS ip_hash VARCHAR(32) NOT NULL DEFAULT '';
+
+-- clicks before anonymized_to have no identifiers left: IP truncated to a network, no IP hash and user agent
+CREATE TABLE IF NOT EXISTS click_anonymize_watermark
+(
+    id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    anonymized_to TIMESTAMPTZ NOT NULL
+);
+
+INSERT INTO click_anonymize_watermark (anonymized_to)
+SELECT date_trunc('hour', COALESCE(MIN(clicked_at), now()) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+FROM clicks
+ON CONFLICT DO NOTHING;
\ No newline at end of file
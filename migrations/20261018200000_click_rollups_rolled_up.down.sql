@@ -0,0 +1,15 @@
+DROP INDEX IF EXISTS idx_clicks_not_rolled_up;
+ALTER TABLE clicks DROP COLUMN IF EXISTS rolled_up;
+
+-- daily rollups hold completed days only
+DELETE FROM click_rollups_daily
+WHERE bucket >= (SELECT date_trunc('day', rolled_up_to AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' FROM click_rollup_watermark);
+
+-- referrers of rolled up clicks are gone, they are counted as direct traffic
+ALTER TABLE click_rollups_hourly ADD COLUMN IF NOT EXISTS referrer_host VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_hourly DROP CONSTRAINT IF EXISTS click_rollups_hourly_pkey;
+ALTER TABLE click_rollups_hourly ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country, is_bot, os);
+
+ALTER TABLE click_rollups_daily ADD COLUMN IF NOT EXISTS referrer_host VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE click_rollups_daily DROP CONSTRAINT IF EXISTS click_rollups_daily_pkey;
+ALTER TABLE click_rollups_daily ADD PRIMARY KEY (url_id, bucket, browser_family, device, referrer_host, country, is_bot, os);
\ No newline at end of file
@@ -0,0 +1,173 @@
+package encoder
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	StrategySequential = "sequential"
+	StrategyPermuted   = "permuted"
+	StrategyRandom     = "random"
+)
+
+const (
+	_permutedHalfBits   = 18
+	_permutedHalfMask   = 1<<_permutedHalfBits - 1
+	_permutedMaxID      = 1<<(2*_permutedHalfBits) - 1
+	_permutedRounds     = 4
+	_permutedCodeLength = 7 // 62^7 > 2^36
+
+	_minRandomLength = 4
+	_maxRandomLength = 32
+)
+
+var ErrIDOutOfRange = errors.New("id out of range")
+
+// CodeGenerator turns a link ID into a short code.
+type CodeGenerator interface {
+	Generate(id int64) (string, error)
+}
+
+func NewCodeGenerator(strategy string, length int, secret string) (CodeGenerator, error) {
+	switch strategy {
+	case StrategySequential:
+		return NewSequential(), nil
+	case StrategyPermuted:
+		return NewPermuted(secret)
+	case StrategyRandom:
+		return NewRandom(length)
+	default:
+		return nil, fmt.Errorf("encoder - NewCodeGenerator: unknown strategy %q", strategy)
+	}
+}
+
+// Sequential encodes the ID itself: short, but enumerable.
+type Sequential struct{}
+
+func NewSequential() *Sequential {
+	return &Sequential{}
+}
+
+func (s *Sequential) Generate(id int64) (string, error) {
+	return Encode(id), nil
+}
+
+// Permuted encodes a keyed bijection of the ID (Feistel network over 36 bits),
+// so codes look random but never collide and can be decoded back with the same secret.
+type Permuted struct {
+	keys [_permutedRounds]uint64
+}
+
+func NewPermuted(secret string) (*Permuted, error) {
+	if secret == "" {
+		return nil, errors.New("encoder - NewPermuted: secret is required")
+	}
+
+	sum := sha256.Sum256([]byte(secret))
+
+	p := &Permuted{}
+	for i := range p.keys {
+		p.keys[i] = binary.BigEndian.Uint64(sum[i*8:])
+	}
+
+	return p, nil
+}
+
+func (p *Permuted) Generate(id int64) (string, error) {
+	if id < 0 || id > _permutedMaxID {
+		return "", fmt.Errorf("encoder - Permuted - Generate: %w", ErrIDOutOfRange)
+	}
+
+	code := Encode(int64(p.permute(uint64(id)))) //nolint:gosec
+
+	return strings.Repeat(string(base62Chars[0]), _permutedCodeLength-len(code)) + code, nil
+}
+
+func (p *Permuted) Decode(code string) (int64, error) {
+	// Decode reads unknown characters as 0, they must not pass for a valid code
+	if len(code) != _permutedCodeLength || strings.Trim(code, base62Chars) != "" {
+		return 0, fmt.Errorf("encoder - Permuted - Decode: %w", ErrIDOutOfRange)
+	}
+
+	x := Decode(code)
+	if x < 0 || x > _permutedMaxID {
+		return 0, fmt.Errorf("encoder - Permuted - Decode: %w", ErrIDOutOfRange)
+	}
+
+	return int64(p.unpermute(uint64(x))), nil //nolint:gosec
+}
+
+func (p *Permuted) permute(x uint64) uint64 {
+	l, r := x>>_permutedHalfBits&_permutedHalfMask, x&_permutedHalfMask
+
+	for i := 0; i < _permutedRounds; i++ {
+		l, r = r, l^p.round(r, i)
+	}
+
+	return l<<_permutedHalfBits | r
+}
+
+func (p *Permuted) unpermute(x uint64) uint64 {
+	l, r := x>>_permutedHalfBits&_permutedHalfMask, x&_permutedHalfMask
+
+	for i := _permutedRounds - 1; i >= 0; i-- {
+		l, r = r^p.round(l, i), l
+	}
+
+	return l<<_permutedHalfBits | r
+}
+
+func (p *Permuted) round(half uint64, i int) uint64 {
+	// splitmix64 finalizer
+	z := half ^ p.keys[i]
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	z ^= z >> 31
+
+	return z & _permutedHalfMask
+}
+
+// Random ignores the ID and returns cryptographically random codes of fixed length.
+// Collisions are possible, callers retry on a taken code.
+type Random struct {
+	length int
+}
+
+func NewRandom(length int) (*Random, error) {
+	if length < _minRandomLength || length > _maxRandomLength {
+		return nil, fmt.Errorf("encoder - NewRandom: length must be %d-%d", _minRandomLength, _maxRandomLength)
+	}
+
+	return &Random{length: length}, nil
+}
+
+func (g *Random) Generate(_ int64) (string, error) {
+	code := make([]byte, 0, g.length)
+	buf := make([]byte, g.length*2)
+
+	for len(code) < g.length {
+		_, err := rand.Read(buf)
+		if err != nil {
+			return "", fmt.Errorf("encoder - Random - Generate - rand.Read: %w", err)
+		}
+
+		for _, b := range buf {
+			// reject bytes above the largest multiple of 62 to keep the distribution uniform
+			if b >= 248 {
+				continue
+			}
+			code = append(code, base62Chars[int(b)%len(base62Chars)])
+
+			if len(code) == g.length {
+				break
+			}
+		}
+	}
+
+	return string(code), nil
+}
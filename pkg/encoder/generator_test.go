@@ -0,0 +1,145 @@
+package encoder
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPermutedIsBijection(t *testing.T) {
+	p, err := NewPermuted("test")
+	if err != nil {
+		t.Fatalf("NewPermuted: %v", err)
+	}
+
+	const domain = 1 << 16
+
+	seen := make(map[string]int64, domain)
+
+	for id := range int64(domain) {
+		code, err := p.Generate(id)
+		if err != nil {
+			t.Fatalf("Generate(%d): %v", id, err)
+		}
+
+		if len(code) != _permutedCodeLength {
+			t.Fatalf("Generate(%d) = %q, want %d characters", id, code, _permutedCodeLength)
+		}
+
+		if other, ok := seen[code]; ok {
+			t.Fatalf("Generate(%d) = %q, same as for %d", id, code, other)
+		}
+		seen[code] = id
+
+		got, err := p.Decode(code)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", code, err)
+		}
+
+		if got != id {
+			t.Fatalf("Decode(Generate(%d)) = %d", id, got)
+		}
+	}
+}
+
+func TestPermutedRoundTripAtBounds(t *testing.T) {
+	p, err := NewPermuted("test")
+	if err != nil {
+		t.Fatalf("NewPermuted: %v", err)
+	}
+
+	for _, id := range []int64{0, 1, _permutedHalfMask, _permutedHalfMask + 1, _permutedMaxID - 1, _permutedMaxID} {
+		code, err := p.Generate(id)
+		if err != nil {
+			t.Fatalf("Generate(%d): %v", id, err)
+		}
+
+		got, err := p.Decode(code)
+		if err != nil {
+			t.Fatalf("Decode(%q): %v", code, err)
+		}
+
+		if got != id {
+			t.Errorf("Decode(Generate(%d)) = %d", id, got)
+		}
+	}
+}
+
+func TestPermutedDependsOnSecret(t *testing.T) {
+	a, _ := NewPermuted("a")
+	b, _ := NewPermuted("b")
+
+	codeA, _ := a.Generate(42)
+	codeB, _ := b.Generate(42)
+
+	if codeA == codeB {
+		t.Fatalf("different secrets give the same code %q", codeA)
+	}
+}
+
+func TestPermutedRejectsOutOfRange(t *testing.T) {
+	p, err := NewPermuted("test")
+	if err != nil {
+		t.Fatalf("NewPermuted: %v", err)
+	}
+
+	for _, id := range []int64{-1, _permutedMaxID + 1, 1 << 62} {
+		_, err := p.Generate(id)
+		if !errors.Is(err, ErrIDOutOfRange) {
+			t.Errorf("Generate(%d) error = %v, want ErrIDOutOfRange", id, err)
+		}
+	}
+
+	for _, code := range []string{
+		"",
+		"000000",   // too short
+		"00000000", // too long
+		"zzzzzzz",  // above the 36-bit domain
+		"000-000",  // not base62
+	} {
+		_, err := p.Decode(code)
+		if !errors.Is(err, ErrIDOutOfRange) {
+			t.Errorf("Decode(%q) error = %v, want ErrIDOutOfRange", code, err)
+		}
+	}
+}
+
+func TestNewPermutedRequiresSecret(t *testing.T) {
+	_, err := NewPermuted("")
+	if err == nil {
+		t.Fatal("expected an error for an empty secret")
+	}
+}
+
+func TestRandomLengthAndAlphabet(t *testing.T) {
+	for _, length := range []int{_minRandomLength, 8, _maxRandomLength} {
+		g, err := NewRandom(length)
+		if err != nil {
+			t.Fatalf("NewRandom(%d): %v", length, err)
+		}
+
+		for range 100 {
+			code, err := g.Generate(0)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+
+			if len(code) != length {
+				t.Fatalf("Generate() = %q, want %d characters", code, length)
+			}
+
+			if strings.Trim(code, base62Chars) != "" {
+				t.Fatalf("Generate() = %q, want base62 characters only", code)
+			}
+		}
+	}
+}
+
+func TestNewRandomRejectsLength(t *testing.T) {
+	for _, length := range []int{0, _minRandomLength - 1, _maxRandomLength + 1} {
+		_, err := NewRandom(length)
+		if err == nil {
+			t.Errorf("NewRandom(%d): expected an error", length)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+const (
+	_countryCodeLength   = 2
+	_maxRegionCodeLength = 3
+)
+
+// Location is an ISO 3166-1 country code and the ISO 3166-2 subdivision code without the country part,
+// both empty when the address is unknown.
+type Location struct {
+	Country string
+	Region  string
+}
+
+// record holds the fields of GeoLite2/GeoIP2 Country and City databases we need,
+// country databases have no subdivisions.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Locator looks addresses up in a local MaxMind DB file, no network calls are made.
+type Locator struct {
+	reader *maxminddb.Reader
+}
+
+func New(path string) (*Locator, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("geoip - New - maxminddb.Open: %w", err)
+	}
+
+	return &Locator{reader: reader}, nil
+}
+
+func (l *Locator) Lookup(ip string) (Location, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return Location{}, nil
+	}
+
+	var rec record
+
+	err := l.reader.Lookup(addr, &rec)
+	if err != nil {
+		return Location{}, fmt.Errorf("geoip - Lookup - l.reader.Lookup: %w", err)
+	}
+
+	var loc Location
+
+	// codes of unexpected length come from a foreign or broken database and are dropped
+	if len(rec.Country.ISOCode) == _countryCodeLength {
+		loc.Country = rec.Country.ISOCode
+	}
+	if len(rec.Subdivisions) > 0 && len(rec.Subdivisions[0].ISOCode) <= _maxRegionCodeLength {
+		loc.Region = rec.Subdivisions[0].ISOCode
+	}
+
+	return loc, nil
+}
+
+func (l *Locator) Close() error {
+	return l.reader.Close()
+}
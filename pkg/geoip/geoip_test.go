@@ -0,0 +1,52 @@
+package geoip
+
+import "testing"
+
+// testdata/test-city.mmdb is a City database with a few networks:
+// 81.2.69.0/24 GB-ENG, 89.160.20.0/24 SE without subdivisions, 2a02:6b8::/32 RU-MOW
+// and 1.128.0.0/16 with malformed codes AUS and NSWX.
+const _testDB = "testdata/test-city.mmdb"
+
+func TestLookup(t *testing.T) {
+	l, err := New(_testDB)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	tests := []struct {
+		name string
+		ip   string
+		want Location
+	}{
+		{"ipv4", "81.2.69.142", Location{Country: "GB", Region: "ENG"}},
+		{"ipv4 mapped to ipv6", "::ffff:81.2.69.142", Location{Country: "GB", Region: "ENG"}},
+		{"country database record", "89.160.20.128", Location{Country: "SE"}},
+		{"ipv6", "2a02:6b8::feed:0ff", Location{Country: "RU", Region: "MOW"}},
+		{"malformed codes", "1.128.0.1", Location{}},
+		{"ipv4 not in database", "203.0.113.7", Location{}},
+		{"ipv6 not in database", "2001:db8::1", Location{}},
+		{"not an address", "not-an-ip", Location{}},
+		{"empty", "", Location{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := l.Lookup(tt.ip)
+			if err != nil {
+				t.Fatalf("Lookup(%q): %v", tt.ip, err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Lookup(%q) = %+v, want %+v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewMissingFile(t *testing.T) {
+	_, err := New("testdata/missing.mmdb")
+	if err == nil {
+		t.Fatal("expected an error for a missing database")
+	}
+}
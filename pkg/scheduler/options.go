@@ -0,0 +1,11 @@
+package scheduler
+
+import "time"
+
+type Option func(*Scheduler)
+
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Scheduler) {
+		s.shutdownTimeout = timeout
+	}
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
+)
+
+const (
+	_defaultShutdownTimeout = 10 * time.Second
+)
+
+type Job func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       Job
+}
+
+// Scheduler runs registered jobs periodically, each in its own goroutine.
+// A job runs once on Start and then every interval; runs of the same job never overlap.
+type Scheduler struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	jobs []job
+
+	shutdownTimeout time.Duration
+
+	l logger.Interface
+}
+
+func New(l logger.Interface, opts ...Option) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := &Scheduler{
+		ctx:             ctx,
+		cancel:          cancel,
+		shutdownTimeout: _defaultShutdownTimeout,
+		l:               l,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Add registers a job. Must be called before Start.
+func (s *Scheduler) Add(name string, interval time.Duration, fn Job) error {
+	if interval <= 0 {
+		return fmt.Errorf("scheduler - Scheduler - Add: job %s: interval must be positive, got %s", name, interval)
+	}
+
+	s.jobs = append(s.jobs, job{name: name, interval: interval, fn: fn})
+
+	return nil
+}
+
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+
+		go s.run(j)
+	}
+
+	s.l.Info("scheduler - Scheduler - Started %d jobs", len(s.jobs))
+}
+
+func (s *Scheduler) run(j job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+
+		err := j.fn(s.ctx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.l.Error(fmt.Errorf("scheduler - Scheduler - job %s: %w", j.name, err))
+		} else {
+			s.l.Debug("scheduler - Scheduler - job %s finished in %s", j.name, time.Since(start))
+		}
+
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Shutdown cancels running jobs and waits for them to return.
+func (s *Scheduler) Shutdown() error {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.l.Info("scheduler - Scheduler - Shutdown")
+
+		return nil
+	case <-time.After(s.shutdownTimeout):
+		return errors.New("scheduler - Scheduler - Shutdown: timeout")
+	}
+}
@@ -6,4 +6,8 @@ var (
 	ErrRecordNotFound    = errors.New("record not found")
 	ErrInvalidInterval   = errors.New("invalid interval")
 	ErrAliasAlreadyTaken = errors.New("alias already taken")
+	ErrClickQueueFull    = errors.New("click queue is full")
+	ErrLinkExpired       = errors.New("link expired")
+	ErrInvalidFormat     = errors.New("invalid format")
+	ErrStreamLimit       = errors.New("live stream limit reached")
 )
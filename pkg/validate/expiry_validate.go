@@ -0,0 +1,13 @@
+package validate
+
+import "time"
+
+const maxTTLSeconds = 10 * 365 * 24 * 60 * 60
+
+func IsValidTTL(ttlSeconds int64) bool {
+	return ttlSeconds > 0 && ttlSeconds <= maxTTLSeconds
+}
+
+func IsFutureTime(t time.Time) bool {
+	return t.After(time.Now())
+}
@@ -0,0 +1,47 @@
+package workerpool
+
+import "time"
+
+type Option func(*settings)
+
+// Workers sets the number of workers, non-positive numbers keep the default.
+func Workers(n int) Option {
+	return func(s *settings) {
+		if n > 0 {
+			s.workers = n
+		}
+	}
+}
+
+// QueueSize sets the queue capacity, non-positive sizes keep the default.
+func QueueSize(size int) Option {
+	return func(s *settings) {
+		if size > 0 {
+			s.queueSize = size
+		}
+	}
+}
+
+func ShutdownTimeout(timeout time.Duration) Option {
+	return func(s *settings) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// BatchSize sets the maximum number of items handled at once, non-positive sizes keep the default.
+func BatchSize(size int) Option {
+	return func(s *settings) {
+		if size > 0 {
+			s.batchSize = size
+		}
+	}
+}
+
+// FlushInterval sets how often a partial batch is handled, non-positive intervals keep the default.
+func FlushInterval(interval time.Duration) Option {
+	return func(s *settings) {
+		if interval > 0 {
+			s.flushInterval = interval
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package workerpool
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/andreyxaxa/URL-Shortener/pkg/logger"
+)
+
+const (
+	_defaultWorkers         = 4
+	_defaultQueueSize       = 1024
+	_defaultShutdownTimeout = 5 * time.Second
+	_defaultBatchSize       = 1
+	_defaultFlushInterval   = time.Second
+)
+
+// Handler processes a batch of items. The slice is reused after the call returns.
+type Handler[T any] func(ctx context.Context, items []T) error
+
+type Stats struct {
+	Depth     int
+	Capacity  int
+	Enqueued  int64
+	Dropped   int64
+	Processed int64
+	Failed    int64
+}
+
+type settings struct {
+	workers         int
+	queueSize       int
+	shutdownTimeout time.Duration
+	batchSize       int
+	flushInterval   time.Duration
+}
+
+// Pool is a bounded queue served by a fixed number of workers.
+// Submit never blocks: when the queue is full the item is dropped and counted.
+// Each worker hands items to the handler in batches, flushing on batch size or flush interval.
+type Pool[T any] struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan T
+
+	settings
+
+	enqueued  atomic.Int64
+	dropped   atomic.Int64
+	processed atomic.Int64
+	failed    atomic.Int64
+
+	l logger.Interface
+}
+
+func New[T any](l logger.Interface, opts ...Option) *Pool[T] {
+	s := settings{
+		workers:         _defaultWorkers,
+		queueSize:       _defaultQueueSize,
+		shutdownTimeout: _defaultShutdownTimeout,
+		batchSize:       _defaultBatchSize,
+		flushInterval:   _defaultFlushInterval,
+	}
+
+	// Custom options
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Pool[T]{
+		ctx:      ctx,
+		cancel:   cancel,
+		queue:    make(chan T, s.queueSize),
+		settings: s,
+		l:        l,
+	}
+}
+
+func (p *Pool[T]) Start(handler Handler[T]) {
+	for i := 0; i < p.workers; i++ {
+		p.wg.Add(1)
+
+		go p.work(handler)
+	}
+
+	p.l.Info("workerpool - Pool - Started with %d workers, queue size %d, batch size %d",
+		p.workers, p.queueSize, p.batchSize)
+}
+
+func (p *Pool[T]) work(handler Handler[T]) {
+	defer p.wg.Done()
+
+	batch := make([]T, 0, p.batchSize)
+
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		err := handler(p.ctx, batch)
+		if err != nil {
+			p.failed.Add(int64(len(batch)))
+			p.l.Error(err, "workerpool - Pool - handler")
+		} else {
+			p.processed.Add(int64(len(batch)))
+		}
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case item, ok := <-p.queue:
+			if !ok {
+				flush()
+
+				return
+			}
+
+			batch = append(batch, item)
+			if len(batch) >= p.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Submit enqueues item without blocking. Returns false if the item was dropped.
+func (p *Pool[T]) Submit(item T) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		p.dropped.Add(1)
+
+		return false
+	}
+
+	select {
+	case p.queue <- item:
+		p.enqueued.Add(1)
+
+		return true
+	default:
+		p.dropped.Add(1)
+
+		return false
+	}
+}
+
+func (p *Pool[T]) Stats() Stats {
+	return Stats{
+		Depth:     len(p.queue),
+		Capacity:  cap(p.queue),
+		Enqueued:  p.enqueued.Load(),
+		Dropped:   p.dropped.Load(),
+		Processed: p.processed.Load(),
+		Failed:    p.failed.Load(),
+	}
+}
+
+// Shutdown stops accepting new items and waits for the queue to drain.
+// If draining takes longer than the shutdown timeout, in-flight handlers are cancelled.
+func (p *Pool[T]) Shutdown() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+
+		return nil
+	}
+	p.closed = true
+	close(p.queue)
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	defer p.cancel()
+
+	select {
+	case <-done:
+		stats := p.Stats()
+		p.l.Info("workerpool - Pool - Shutdown: processed %d, failed %d, dropped %d",
+			stats.Processed, stats.Failed, stats.Dropped)
+
+		return nil
+	case <-time.After(p.shutdownTimeout):
+		return fmt.Errorf("workerpool - Pool - Shutdown: timeout, %d items left in queue", len(p.queue))
+	}
+}